@@ -16,7 +16,6 @@ import (
 	"image/color"
 	"math"
 	"unicode/utf8"
-	"unsafe"
 )
 
 // init precomputa la tabla de dígitos para optimizar la conversión a ANSI
@@ -149,77 +148,74 @@ func ResetAllColors() []byte {
 	return []byte("\033[39;49m")
 }
 
-// buffer para almacenar códigos ANSI generados
-// especialmente paralos colores ANSI
-// utiliza bigANSI_Code para ambos colores
-// y littleANSI_Code para un solo color
-var(
-	bigANSI_Code [36]byte
-	littleANSI_Code [19]byte
-)
-
 // PaintBase genera el código ANSI para aplicar colores de texto y fondo
 // Si ambos colores son opacos, usa el código extendido para ambos
+// Cada llamada devuelve un slice propio, por lo que es seguro usarla desde varias goroutines
 func PaintBase(fgColor, bgColor color.RGBA) (block []byte) {
-	var buf []byte
+	block = make([]byte, 0, 36)
+	AppendPaintBase(&block, fgColor, bgColor)
+
+	return block
+}
 
+// AppendPaintBase agrega al búfer el código ANSI para aplicar colores de texto y fondo.
+// Es la variante sin asignaciones de PaintBase: el búfer pertenece al llamador.
+func AppendPaintBase(buf *[]byte, fgColor, bgColor color.RGBA) {
 	bgAlpha := bgColor.A > ALPHA_1
 	fgAlpha := fgColor.A > ALPHA_1
 
 	if bgAlpha && fgAlpha {
-		buf = bigANSI_Code[:0]
 		GetANSI_DoubleColor(
-			&buf, fgColor.R, fgColor.G, fgColor.B, bgColor.R, bgColor.G, bgColor.B)
-		
-		return buf
+			buf, fgColor.R, fgColor.G, fgColor.B, bgColor.R, bgColor.G, bgColor.B)
+
+		return
 	}
 
-	buf = littleANSI_Code[:0]
 	if bgAlpha {
-		GetANSI_Color(&buf, bgColor.R, bgColor.G, bgColor.B, false)
+		GetANSI_Color(buf, bgColor.R, bgColor.G, bgColor.B, false)
 	} else if fgAlpha {
-		GetANSI_Color(&buf, fgColor.R, fgColor.G, fgColor.B, true)
+		GetANSI_Color(buf, fgColor.R, fgColor.G, fgColor.B, true)
 	}
-
-	return buf
 }
 
-// ansiBlock es un búfer reutilizable para construir cadenas ANSI 
-// y evitar asignaciones repetidas
-var ansiBlock 	[47]byte                                                              
-
 // PaintRune genera el código ANSI para un solo carácter con colores específicos.
 // Si resetColor es true, agrega el código para resetear los colores al final.
 func PaintRune(character rune, textColor, backgroundColor color.RGBA, resetColor bool) (block []byte) {
-	buf := ansiBlock[:0]
+	block = make([]byte, 0, 47)
+	AppendPaintRune(&block, character, textColor, backgroundColor, resetColor)
 
-	// Conversión directa del rune
-	buf = append(buf, PaintBase(textColor, backgroundColor)...)
-	utf8.EncodeRune(buf[len(buf):len(buf)+3], character)
-	buf = buf[:len(buf)+utf8.RuneLen(character)]
+	return block
+}
+
+// AppendPaintRune agrega al búfer un carácter con colores específicos.
+// Si resetColor es true, agrega el código para resetear los colores al final.
+func AppendPaintRune(buf *[]byte, character rune, textColor, backgroundColor color.RGBA, resetColor bool) {
+	AppendPaintBase(buf, textColor, backgroundColor)
+	*buf = utf8.AppendRune(*buf, character)
 
 	if resetColor {
-		buf = append(buf, ResetAllColors()...)
+		*buf = append(*buf, ResetAllColors()...)
 	}
-	return buf
 }
 
 // PaintString genera el código ANSI para una cadena de texto con colores específicos.
 // Si resetColor es true, agrega el código para resetear los colores al final.
 func PaintString(text string, textColor, backgroundColor color.RGBA, resetColor bool) (block string) {
-	buf := ansiBlock[:0]
-	buf = append(buf, PaintBase(textColor, backgroundColor)...)
+	buf := make([]byte, 0, 47+len(text))
+	AppendPaintString(&buf, text, textColor, backgroundColor, resetColor)
 
-	for _, r := range text {
-        var charBuf [4]byte // Máximo tamaño de un rune en UTF-8
-        n := utf8.EncodeRune(charBuf[:], r)
-        buf = append(buf, charBuf[:n]...)
-    }
+	return string(buf)
+}
+
+// AppendPaintString agrega al búfer una cadena de texto con colores específicos.
+// Si resetColor es true, agrega el código para resetear los colores al final.
+func AppendPaintString(buf *[]byte, text string, textColor, backgroundColor color.RGBA, resetColor bool) {
+	AppendPaintBase(buf, textColor, backgroundColor)
+	*buf = append(*buf, text...)
 
 	if resetColor {
-		buf = append(buf, ResetAllColors()...)
+		*buf = append(*buf, ResetAllColors()...)
 	}
-	return unsafe.String(unsafe.SliceData(buf), len(buf))
 }
 
 // BlockShade devuelve un carácter Unicode que representa el nivel de 
@@ -235,10 +231,10 @@ func AverageColor(color1, color2 color.RGBA) (color3 color.RGBA) {
 	R1, G1, B1, A1 := color1.RGBA()
 	R2, G2, B2, A2 := color2.RGBA()
 	return color.RGBA{
-		R: uint8(R1>>8 + R2>>8) / 2,
-		G: uint8(G1>>8 + G2>>8) / 2,
-		B: uint8(B1>>8 + B2>>8) / 2,
-		A: uint8(A1>>8 + A2>>8) / 2,
+		R: uint8((R1>>8 + R2>>8) / 2),
+		G: uint8((G1>>8 + G2>>8) / 2),
+		B: uint8((B1>>8 + B2>>8) / 2),
+		A: uint8((A1>>8 + A2>>8) / 2),
 	}
 }
 
@@ -247,6 +243,6 @@ func AverageAlpha(color1, color2 color.RGBA) (color3 color.RGBA) {
 	_, _, _, A1 := color1.RGBA()
 	_, _, _, A2 := color2.RGBA()
 	return color.RGBA{
-		A: uint8(A1>>8 + A2>>8) / 2,
+		A: uint8((A1>>8 + A2>>8) / 2),
 	}
 }
\ No newline at end of file
@@ -0,0 +1,93 @@
+package ansi
+
+import (
+	"fmt"
+	"image/color"
+	"sync"
+	"testing"
+	"unsafe"
+)
+
+// TestPaintConcurrent llama a PaintString y PaintRune desde varias goroutines y comprueba
+// que cada resultado es el esperado y que ninguno comparte memoria con otro
+// Se debe ejecutar con 'go test -race'
+func TestPaintConcurrent(t *testing.T) {
+	const goroutines = 16
+	const calls = 200
+
+	type result struct {
+		text	string
+		rune	[]byte
+		want	string
+		wantRune string
+	}
+
+	results := make([][]result, goroutines)
+	var wg sync.WaitGroup
+	for g := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range calls {
+				fg := color.RGBA{R: uint8(g), G: uint8(i), B: uint8(g + i), A: 255}
+				bg := color.RGBA{R: uint8(i), G: uint8(g), B: 7, A: 255}
+				text := fmt.Sprintf("g%d-%d", g, i)
+
+				results[g] = append(results[g], result{
+					text:		PaintString(text, fg, bg, true),
+					rune:		PaintRune('▀', fg, bg, i % 2 == 0),
+					want:		fmt.Sprintf("\033[38;2;%d;%d;%d;48;2;%d;%d;%dm%s\033[39;49m", fg.R, fg.G, fg.B, bg.R, bg.G, bg.B, text),
+					wantRune:	fmt.Sprintf("\033[38;2;%d;%d;%d;48;2;%d;%d;%dm▀", fg.R, fg.G, fg.B, bg.R, bg.G, bg.B),
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Direcciones del primer byte de cada resultado: si dos coinciden, comparten memoria
+	seen := make(map[*byte]string)
+	owner := func(data *byte, name string) {
+		if other, ok := seen[data]; ok { t.Errorf("%s comparte memoria con %s", name, other) }
+		seen[data] = name
+	}
+
+	for g, list := range results {
+		for i, r := range list {
+			name := fmt.Sprintf("goroutine %d, llamada %d", g, i)
+
+			if r.text != r.want { t.Errorf("%s: PaintString = %q, se esperaba %q", name, r.text, r.want) }
+
+			wantRune := r.wantRune
+			if i % 2 == 0 { wantRune += "\033[39;49m" }
+			if string(r.rune) != wantRune { t.Errorf("%s: PaintRune = %q, se esperaba %q", name, r.rune, wantRune) }
+
+			owner(unsafe.StringData(r.text), name + " (PaintString)")
+			owner(&r.rune[0], name + " (PaintRune)")
+		}
+	}
+}
+
+// TestPaintRuneOwned comprueba que modificar el resultado de PaintRune no afecta a las llamadas siguientes
+func TestPaintRuneOwned(t *testing.T) {
+	fg := color.RGBA{R: 1, G: 2, B: 3, A: 255}
+	bg := color.RGBA{R: 4, G: 5, B: 6, A: 255}
+
+	first := PaintRune('▄', fg, bg, false)
+	want := string(first)
+	for i := range first { first[i] = 'x' }
+
+	second := PaintRune('▄', fg, bg, false)
+	if string(second) != want { t.Errorf("PaintRune = %q despues de modificar otro resultado, se esperaba %q", second, want) }
+}
+
+// TestAverage comprueba el promedio de canales cuya suma no cabe en un byte
+func TestAverage(t *testing.T) {
+	a := color.RGBA{R: 200, G: 128, B: 10, A: 128}
+	b := color.RGBA{R: 100, G: 128, B: 250, A: 200}
+
+	if got, want := AverageColor(a, b), (color.RGBA{R: 150, G: 128, B: 130, A: 164}); got != want {
+		t.Errorf("AverageColor = %v, se esperaba %v", got, want)
+	}
+	if got := AverageAlpha(a, a); got.A != 128 { t.Errorf("AverageAlpha = %d, se esperaba 128", got.A) }
+	if got := BlockShade(AverageAlpha(a, a)); got != '▒' { t.Errorf("BlockShade = %q, se esperaba '▒'", got) }
+}
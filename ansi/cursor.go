@@ -51,3 +51,14 @@ func MoveTo(column, lines int) string {
 func MoveToColumn(column int) string {
 	return Esc + fmt.Sprintf("%dG", column)
 }
+
+// SaveCursor guarda la posición del cursor y el estilo actual (DECSC, ESC 7)
+// Se recomienda frente a la secuencia SCO (ESC[s), ver la nota del inicio
+func SaveCursor() string {
+	return "\0337"
+}
+
+// RestoreCursor vuelve a la posición y el estilo guardados con SaveCursor (DECRC, ESC 8)
+func RestoreCursor() string {
+	return "\0338"
+}
@@ -0,0 +1,402 @@
+package ansi
+
+/*
+Decodificador de la entrada del terminal en modo crudo.
+Convierte los bytes que envia el terminal en eventos de teclado y de raton:
+	ESC [ A .. D			flechas (ESC O A .. D en modo aplicacion)
+	ESC [ 1 ; 5 A			flecha con modificadores (1 + shift=1, alt=2, ctrl=4)
+	ESC [ 5 ~ / ESC [ 6 ~	PgUp / PgDn
+	ESC [ < b ; x ; y M		raton SGR (1006), 'm' al soltar
+	ESC [ M b x y			raton X10 (coordenadas + 32)
+y las respuestas a las consultas al terminal (ver query.go) como ReportEvent:
+	ESC [ ? ... c, ESC [ ? ... $y, ESC [ ... t, ESC [ fila ; columna R, ESC ] ... ST
+*/
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Key identifica una tecla especial; KeyRune indica un caracter imprimible (ver KeyEvent.Rune)
+type Key int
+
+// Teclas especiales reconocidas por el decodificador
+const (
+	KeyRune Key = iota
+	KeyEsc
+	KeyEnter
+	KeyTab
+	KeyBackspace
+	KeyUp
+	KeyDown
+	KeyRight
+	KeyLeft
+	KeyHome
+	KeyEnd
+	KeyPgUp
+	KeyPgDn
+	KeyInsert
+	KeyDelete
+	KeyF1
+	KeyF2
+	KeyF3
+	KeyF4
+	KeyF5
+	KeyF6
+	KeyF7
+	KeyF8
+	KeyF9
+	KeyF10
+	KeyF11
+	KeyF12
+)
+
+// Modifier es el conjunto de modificadores pulsados junto con una tecla o el raton
+type Modifier uint8
+
+// Modificadores de teclado
+const (
+	ModShift Modifier = 1 << iota
+	ModAlt
+	ModCtrl
+)
+
+// MouseButton identifica el boton (o la rueda) de un evento de raton
+type MouseButton int
+
+// Botones del raton
+const (
+	MouseNone MouseButton = iota	// movimiento sin botones pulsados
+	MouseLeft
+	MouseMiddle
+	MouseRight
+	MouseWheelUp
+	MouseWheelDown
+	MouseWheelLeft
+	MouseWheelRight
+)
+
+// MouseAction es lo que ocurrio con el boton
+type MouseAction int
+
+// Acciones del raton
+const (
+	MousePress MouseAction = iota
+	MouseRelease
+	MouseMotion
+)
+
+// InputEvent es un evento de entrada: KeyEvent, MouseEvent, ReportEvent o UnknownEvent
+type InputEvent interface {
+	isInputEvent()
+}
+
+// KeyEvent es una tecla pulsada
+type KeyEvent struct {
+	Key		Key
+	Rune	rune		// solo si Key es KeyRune
+	Mod		Modifier
+}
+
+// MouseEvent es un evento de raton; X e Y son la columna y la fila (desde 0)
+type MouseEvent struct {
+	X, Y	int
+	Button	MouseButton
+	Action	MouseAction
+	Mod		Modifier
+}
+
+// ReportEvent es la respuesta del terminal a una consulta (ver RequestCursorPosition,
+// RequestBackgroundColor...)
+type ReportEvent struct {
+	Sequence	[]byte
+}
+
+// UnknownEvent es una secuencia que el decodificador no reconoce
+type UnknownEvent struct {
+	Sequence	[]byte
+}
+
+func (KeyEvent) isInputEvent()		{}
+func (MouseEvent) isInputEvent()	{}
+func (ReportEvent) isInputEvent()	{}
+func (UnknownEvent) isInputEvent()	{}
+
+// Token analiza la respuesta (ver Parser)
+func (report ReportEvent) Token() Token {
+	tokens := Tokenize(report.Sequence)
+	if len(tokens) == 0 { return Token{} }
+
+	return tokens[0]
+}
+
+// IsRune indica si el evento es el caracter r sin ctrl ni alt
+func (key KeyEvent) IsRune(r rune) bool {
+	return key.Key == KeyRune && key.Rune == r && key.Mod & (ModCtrl | ModAlt) == 0
+}
+
+// IsCtrl indica si el evento es ctrl + la letra r (en minuscula)
+func (key KeyEvent) IsCtrl(r rune) bool {
+	return key.Key == KeyRune && key.Rune == r && key.Mod & ModCtrl != 0
+}
+
+// keyNames son los nombres de las teclas especiales (ver KeyEvent.String)
+var keyNames = map[Key]string{
+	KeyEsc: "esc", KeyEnter: "enter", KeyTab: "tab", KeyBackspace: "backspace",
+	KeyUp: "up", KeyDown: "down", KeyRight: "right", KeyLeft: "left",
+	KeyHome: "home", KeyEnd: "end", KeyPgUp: "pgup", KeyPgDn: "pgdn",
+	KeyInsert: "insert", KeyDelete: "delete",
+	KeyF1: "f1", KeyF2: "f2", KeyF3: "f3", KeyF4: "f4", KeyF5: "f5", KeyF6: "f6",
+	KeyF7: "f7", KeyF8: "f8", KeyF9: "f9", KeyF10: "f10", KeyF11: "f11", KeyF12: "f12",
+}
+
+// String devuelve el nombre de la tecla, por ejemplo "a", "ctrl+c", "shift+up" o "space"
+func (key KeyEvent) String() string {
+	var name []byte
+	if key.Mod & ModCtrl != 0	{ name = append(name, "ctrl+"...) }
+	if key.Mod & ModAlt != 0	{ name = append(name, "alt+"...) }
+	if key.Mod & ModShift != 0	{ name = append(name, "shift+"...) }
+
+	switch {
+	case key.Key != KeyRune:	name = append(name, keyNames[key.Key]...)
+	case key.Rune == ' ':		name = append(name, "space"...)
+	default:					name = utf8.AppendRune(name, key.Rune)
+	}
+
+	return string(name)
+}
+
+// DecodeInput decodifica el primer evento de p y devuelve cuantos bytes consumio.
+// Si p contiene solo el comienzo de una secuencia devuelve n == 0 (faltan bytes);
+// un ESC solo al final de p se interpreta como la tecla Esc.
+func DecodeInput(p []byte) (event InputEvent, n int) {
+	if len(p) == 0 { return nil, 0 }
+
+	switch b := p[0]; {
+	case b == 0x1b:
+		return decodeEscape(p)
+	case b == '\r' || b == '\n':
+		return KeyEvent{Key: KeyEnter}, 1
+	case b == '\t':
+		return KeyEvent{Key: KeyTab}, 1
+	case b == 0x7f || b == 0x08:
+		return KeyEvent{Key: KeyBackspace}, 1
+	case b == 0:
+		return KeyEvent{Key: KeyRune, Rune: ' ', Mod: ModCtrl}, 1
+	case b < 0x20:
+		return KeyEvent{Key: KeyRune, Rune: rune('a' + b - 1), Mod: ModCtrl}, 1
+	}
+
+	if !utf8.FullRune(p) { return nil, 0 }
+
+	r, size := utf8.DecodeRune(p)
+	return KeyEvent{Key: KeyRune, Rune: r}, size
+}
+
+// decodeEscape decodifica una secuencia que empieza con ESC
+func decodeEscape(p []byte) (event InputEvent, n int) {
+	if len(p) == 1 { return KeyEvent{Key: KeyEsc}, 1 }
+
+	switch p[1] {
+	case '[':
+		return decodeCSI(p)
+	case ']', 'P', '_':
+		// Una respuesta llega entera en la misma lectura; ESC ] solo es alt + ]
+		if len(p) > 2 { return decodeString(p) }
+	case 'O':
+		if len(p) < 3 { return nil, 0 }
+		return decodeSS3(p[2]), 3
+	case 0x1b:
+		return KeyEvent{Key: KeyEsc}, 1
+	}
+
+	// ESC + tecla -> alt + tecla
+	event, n = DecodeInput(p[1:])
+	if n == 0 { return nil, 0 }
+
+	if key, ok := event.(KeyEvent); ok {
+		key.Mod |= ModAlt
+		return key, n + 1
+	}
+	return UnknownEvent{Sequence: bytes.Clone(p[:n+1])}, n + 1
+}
+
+// decodeSS3 decodifica las teclas en modo aplicacion (ESC O x)
+func decodeSS3(final byte) InputEvent {
+	switch final {
+	case 'A': return KeyEvent{Key: KeyUp}
+	case 'B': return KeyEvent{Key: KeyDown}
+	case 'C': return KeyEvent{Key: KeyRight}
+	case 'D': return KeyEvent{Key: KeyLeft}
+	case 'H': return KeyEvent{Key: KeyHome}
+	case 'F': return KeyEvent{Key: KeyEnd}
+	case 'P': return KeyEvent{Key: KeyF1}
+	case 'Q': return KeyEvent{Key: KeyF2}
+	case 'R': return KeyEvent{Key: KeyF3}
+	case 'S': return KeyEvent{Key: KeyF4}
+	}
+
+	return UnknownEvent{Sequence: []byte{0x1b, 'O', final}}
+}
+
+// decodeCSI decodifica una secuencia ESC [ parametros final
+func decodeCSI(p []byte) (event InputEvent, n int) {
+	// Raton X10: ESC [ M b x y
+	if len(p) >= 3 && p[2] == 'M' {
+		if len(p) < 6 { return nil, 0 }
+		return decodeMouse(int(p[3]) - 32, int(p[4]) - 32, int(p[5]) - 32, false), 6
+	}
+
+	// Parametros (0x30-0x3f), intermedios (0x20-0x2f) y byte final (0x40-0x7e)
+	end := 2
+	for end < len(p) && p[end] >= 0x20 && p[end] <= 0x3f { end++ }
+	if end == len(p) { return nil, 0 }
+
+	final := p[end]
+	n = end + 1
+	if final < 0x40 || final > 0x7e { return UnknownEvent{Sequence: bytes.Clone(p[:n])}, n }
+
+	params := p[2:end]
+	if len(params) > 0 && params[0] == '<' {
+		values := parseParams(params[1:])
+		if len(values) != 3 || (final != 'M' && final != 'm') {
+			return UnknownEvent{Sequence: bytes.Clone(p[:n])}, n
+		}
+		return decodeMouse(values[0], values[1], values[2], final == 'm'), n
+	}
+
+	// Respuestas: privadas (DA1, DECRPM), XTWINOPS y estado (DSR)
+	if (len(params) > 0 && params[0] >= '=' && params[0] <= '?') || final == 't' || final == 'y' || final == 'n' {
+		return ReportEvent{Sequence: bytes.Clone(p[:n])}, n
+	}
+
+	values := parseParams(params)
+
+	// ESC [ 1 ; m R es F3 con modificadores; con otra fila o columna es la posicion del cursor
+	if final == 'R' && len(values) == 2 && (values[0] != 1 || values[1] > 8) {
+		return ReportEvent{Sequence: bytes.Clone(p[:n])}, n
+	}
+
+	mod := Modifier(0)
+	if len(values) >= 2 && values[1] > 1 { mod = Modifier(values[1] - 1) }
+
+	var key Key
+	switch final {
+	case 'A': key = KeyUp
+	case 'B': key = KeyDown
+	case 'C': key = KeyRight
+	case 'D': key = KeyLeft
+	case 'H': key = KeyHome
+	case 'F': key = KeyEnd
+	case 'P': key = KeyF1
+	case 'Q': key = KeyF2
+	case 'R': key = KeyF3
+	case 'S': key = KeyF4
+	case 'Z': return KeyEvent{Key: KeyTab, Mod: ModShift}, n
+	case '~':
+		if len(values) == 0 { return UnknownEvent{Sequence: bytes.Clone(p[:n])}, n }
+		key = tildeKeys[values[0]]
+	}
+
+	if key == KeyRune { return UnknownEvent{Sequence: bytes.Clone(p[:n])}, n }
+	return KeyEvent{Key: key, Mod: mod}, n
+}
+
+// decodeString decodifica una cadena OSC, DCS o APC terminada en BEL o ST (ESC \\)
+func decodeString(p []byte) (event InputEvent, n int) {
+	for i := 2; i < len(p); i++ {
+		switch {
+		case p[i] == 0x07:
+			return ReportEvent{Sequence: bytes.Clone(p[:i+1])}, i + 1
+		case p[i] == 0x1b && i + 1 == len(p):
+			return nil, 0
+		case p[i] == 0x1b && p[i+1] == '\\':
+			return ReportEvent{Sequence: bytes.Clone(p[:i+2])}, i + 2
+		case p[i] == 0x1b || p[i] < 0x20:
+			// Cadena cortada: se descarta hasta aqui
+			return UnknownEvent{Sequence: bytes.Clone(p[:i])}, i
+		}
+	}
+	return nil, 0
+}
+
+// tildeKeys son las teclas con la forma ESC [ n ~
+var tildeKeys = map[int]Key{
+	1: KeyHome, 2: KeyInsert, 3: KeyDelete, 4: KeyEnd, 5: KeyPgUp, 6: KeyPgDn, 7: KeyHome, 8: KeyEnd,
+	11: KeyF1, 12: KeyF2, 13: KeyF3, 14: KeyF4, 15: KeyF5, 17: KeyF6, 18: KeyF7, 19: KeyF8,
+	20: KeyF9, 21: KeyF10, 23: KeyF11, 24: KeyF12,
+}
+
+// decodeMouse convierte el codigo de boton y las coordenadas (desde 1) en un MouseEvent
+func decodeMouse(code, x, y int, release bool) MouseEvent {
+	event := MouseEvent{X: x - 1, Y: y - 1, Action: MousePress}
+
+	if code & 4 != 0	{ event.Mod |= ModShift }
+	if code & 8 != 0	{ event.Mod |= ModAlt }
+	if code & 16 != 0	{ event.Mod |= ModCtrl }
+	if code & 32 != 0	{ event.Action = MouseMotion }
+	if release			{ event.Action = MouseRelease }
+
+	button := code & 3
+	switch {
+	case code & 64 != 0:
+		event.Button = MouseWheelUp + MouseButton(button)
+	case button == 3:
+		// X10 informa la liberacion de cualquier boton como el boton 3
+		event.Button = MouseNone
+		if event.Action == MousePress { event.Action = MouseRelease }
+	default:
+		event.Button = MouseLeft + MouseButton(button)
+	}
+
+	return event
+}
+
+// parseParams convierte "1;5" en [1 5]; los parametros vacios valen 0
+func parseParams(params []byte) []int {
+	if len(params) == 0 { return nil }
+
+	var values []int
+	for field := range bytes.SplitSeq(params, []byte{';'}) {
+		value, _ := strconv.Atoi(string(field))
+		values = append(values, value)
+	}
+
+	return values
+}
+
+// InputDecoder lee eventos de entrada desde un io.Reader (normalmente la entrada
+// estandar en modo crudo)
+type InputDecoder struct {
+	reader	io.Reader
+	buf		[]byte
+	chunk	[256]byte
+}
+
+// NewInputDecoder crea un decodificador que lee de r
+func NewInputDecoder(r io.Reader) *InputDecoder {
+	return &InputDecoder{reader: r}
+}
+
+// ReadEvent bloquea hasta leer el siguiente evento completo
+// Un ESC que llega solo en una lectura se interpreta como la tecla Esc
+func (d *InputDecoder) ReadEvent() (InputEvent, error) {
+	for {
+		if event, n := DecodeInput(d.buf); n > 0 {
+			d.buf = d.buf[n:]
+			return event, nil
+		}
+
+		read, err := d.reader.Read(d.chunk[:])
+		if read == 0 && err != nil { return nil, err }
+
+		d.buf = append(d.buf, d.chunk[:read]...)
+	}
+}
+
+// Buffered indica si quedan bytes leidos sin decodificar
+func (d *InputDecoder) Buffered() bool {
+	return len(d.buf) > 0
+}
@@ -0,0 +1,123 @@
+package ansi
+
+/*
+Secuencias OSC (Operating System Command): ESC ] Ps ; Pt ST
+	0	titulo de la ventana y del icono	ESC ]0;titulo ST
+	2	titulo de la ventana				ESC ]2;titulo ST
+	8	enlace							ESC ]8;id=x;url ST texto ESC ]8;; ST
+	52	portapapeles					ESC ]52;c;base64 ST
+El terminador estandar es ST (ESC \); algunos terminales antiguos solo aceptan BEL.
+Dentro de tmux las secuencias que tmux no entiende se deben envolver (ver TmuxPassthrough),
+y tmux solo las deja pasar con "set -g allow-passthrough on".
+*/
+
+import (
+	"encoding/base64"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Inicio y terminadores de las secuencias OSC
+const (
+	OSC	= "\033]"
+	ST	= "\033\\"
+	BEL	= "\a"
+)
+
+// OSCTerminator es el terminador de las secuencias OSC de este paquete (ST o BEL)
+var OSCTerminator = ST
+
+// OSCSequence genera la secuencia OSC 'command' con los datos indicados
+// Los controles de 'payload' se descartan, ya que cortarian la secuencia
+func OSCSequence(command int, payload string) string {
+	return OSC + strconv.Itoa(command) + ";" + stripControls(payload) + OSCTerminator
+}
+
+// stripControls quita los controles C0 y C1 (ESC, BEL...) de un texto
+func stripControls(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || (r >= 0x7f && r < 0xa0) { return -1 }
+		return r
+	}, text)
+}
+
+// SetTitle cambia el titulo de la ventana y del icono (OSC 0)
+func SetTitle(title string) string {
+	return OSCSequence(0, title)
+}
+
+// SetWindowTitle cambia el titulo de la ventana (OSC 2)
+func SetWindowTitle(title string) string {
+	return OSCSequence(2, title)
+}
+
+// PushTitle guarda el titulo actual en la pila del terminal (XTWINOPS 22)
+func PushTitle() string {
+	return Esc + "22;0t"
+}
+
+// PopTitle restaura el ultimo titulo guardado con PushTitle (XTWINOPS 23)
+func PopTitle() string {
+	return Esc + "23;0t"
+}
+
+// HyperlinkStart abre un enlace (OSC 8): el texto que sigue hasta HyperlinkEnd apunta a 'link'
+// 'id' (opcional) une en un solo enlace los trozos separados del mismo texto, por ejemplo
+// al cortarse en varias lineas
+func HyperlinkStart(link, id string) string {
+	// ':', ';' y '=' separan los parametros del enlace
+	id = strings.Map(func(r rune) rune {
+		if r == ':' || r == ';' || r == '=' { return -1 }
+		return r
+	}, id)
+
+	params := ""
+	if id != "" { params = "id=" + id }
+
+	return OSCSequence(8, params + ";" + link)
+}
+
+// HyperlinkEnd cierra el enlace abierto con HyperlinkStart
+func HyperlinkEnd() string {
+	return OSCSequence(8, ";")
+}
+
+// Hyperlink devuelve el texto como enlace a 'link'
+// Los terminales sin OSC 8 muestran solo el texto
+func Hyperlink(link, text string) string {
+	return HyperlinkStart(link, "") + text + HyperlinkEnd()
+}
+
+// FileURL devuelve el enlace file:// de un archivo, con la ruta absoluta y el nombre del equipo
+// (el terminal solo abre el enlace si el archivo esta en su mismo equipo, ver OSC 8)
+func FileURL(path string) string {
+	if absolute, err := filepath.Abs(path); err == nil { path = absolute }
+
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") { path = "/" + path }	// C:/... en Windows
+
+	host, _ := os.Hostname()
+	return (&url.URL{Scheme: "file", Host: host, Path: path}).String()
+}
+
+// SetClipboard copia el texto al portapapeles del terminal (OSC 52), incluso por SSH
+// Muchos terminales lo desactivan por defecto o limitan su tamaño
+func SetClipboard(text string) string {
+	return OSCSequence(52, "c;" + base64.StdEncoding.EncodeToString([]byte(text)))
+}
+
+// TmuxPassthrough envuelve una secuencia para que tmux la entregue tal cual al terminal
+// exterior: ESC Ptmux; con cada ESC duplicado, terminada en ST
+func TmuxPassthrough(sequence string) string {
+	return "\033Ptmux;" + strings.ReplaceAll(sequence, "\033", "\033\033") + ST
+}
+
+// Passthrough envuelve la secuencia con TmuxPassthrough si el programa corre dentro de tmux
+// (variable TMUX); si no la devuelve sin cambios
+func Passthrough(sequence string) string {
+	if os.Getenv("TMUX") == "" { return sequence }
+	return TmuxPassthrough(sequence)
+}
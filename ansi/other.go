@@ -5,6 +5,10 @@ Modos que funcionan en PowerShell moderno:
 	El texto salta automáticamente a la siguiente línea al llegar al borde. Puedes desactivarlo con ESC[=7l.
 */
 
+import (
+	"fmt"
+)
+
 // ShowCursor muestra u oculta el cursor en la terminal.
 // Parámetro 'show': true para mostrar, false para ocultar.
 func ShowCursor(show bool) string {
@@ -24,4 +28,46 @@ func Auto_Wrap(isActive bool) string {
 func AlternativeScreen(isActive bool) string {
 	if isActive {return Esc + "?1049h"
 	} else 		{return Esc + "?1049l"}	
-}
\ No newline at end of file
+}
+// Modos de seguimiento del raton (DECSET)
+const (
+	MouseClicks	= 1000	// pulsaciones y liberaciones
+	MouseDrag	= 1002	// ademas, movimiento con un boton pulsado
+	MouseAll	= 1003	// ademas, movimiento sin botones
+	MouseSGR	= 1006	// codificacion ESC[<b;x;yM, sin limite de 223 columnas
+)
+
+// MouseMode activa o desactiva uno de los modos de raton (MouseClicks, MouseDrag, MouseAll, MouseSGR).
+func MouseMode(mode int, isActive bool) string {
+	return PrivateMode(mode, isActive)
+}
+
+// EnableMouse activa el arrastre del raton con codificacion SGR.
+func EnableMouse() string {
+	return MouseMode(MouseDrag, true) + MouseMode(MouseSGR, true)
+}
+
+// DisableMouse desactiva los modos activados por EnableMouse, en orden inverso.
+func DisableMouse() string {
+	return MouseMode(MouseSGR, false) + MouseMode(MouseDrag, false)
+}
+
+// Modos privados (DECSET) que cambian el renderizado y los visores
+const (
+	ModeAutoWrap		= 7		// ajuste automatico de linea (Auto_Wrap)
+	ModeCursorVisible	= 25	// cursor visible (ShowCursor)
+	ModeAltScreen		= 1049	// pantalla alternativa (AlternativeScreen)
+	ModeBracketedPaste	= 2004	// pegado entre ESC[200~ y ESC[201~ (BracketedPaste)
+)
+
+// BracketedPaste activa o desactiva el pegado delimitado: el texto pegado llega
+// entre ESC[200~ y ESC[201~ en lugar de como teclas sueltas.
+func BracketedPaste(isActive bool) string {
+	return PrivateMode(ModeBracketedPaste, isActive)
+}
+
+// PrivateMode activa o desactiva cualquier modo privado (DECSET/DECRST), por ejemplo ModeAltScreen.
+func PrivateMode(mode int, isActive bool) string {
+	if isActive {return Esc + fmt.Sprintf("?%dh", mode)
+	} else 		{return Esc + fmt.Sprintf("?%dl", mode)}
+}
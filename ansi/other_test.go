@@ -0,0 +1,37 @@
+package ansi
+
+import (
+	"slices"
+	"testing"
+)
+
+// privateModes devuelve los modos privados que activa (h) o desactiva (l) una salida, en orden
+func privateModes(output string, final byte) []int {
+	var modes []int
+	for _, token := range Tokenize([]byte(output)) {
+		if token.Private != '?' || token.Final != final { continue }
+		for _, param := range token.Params { modes = append(modes, param.Value) }
+	}
+	return modes
+}
+
+// TestDisableMouse comprueba que DisableMouse desactiva exactamente los modos de EnableMouse
+func TestDisableMouse(t *testing.T) {
+	enabled := privateModes(EnableMouse(), 'h')
+	disabled := privateModes(DisableMouse(), 'l')
+
+	if !slices.Equal(enabled, []int{MouseDrag, MouseSGR}) { t.Errorf("EnableMouse activa %v", enabled) }
+
+	slices.Reverse(enabled)
+	if !slices.Equal(disabled, enabled) { t.Errorf("DisableMouse desactiva %v, se esperaba %v", disabled, enabled) }
+}
+
+func TestMouseMode(t *testing.T) {
+	for _, mode := range []int{MouseClicks, MouseDrag, MouseAll, MouseSGR} {
+		for _, isActive := range []bool{true, false} {
+			if got, want := MouseMode(mode, isActive), PrivateMode(mode, isActive); got != want {
+				t.Errorf("MouseMode(%d, %v) = %q, se esperaba %q", mode, isActive, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,518 @@
+package ansi
+
+/*
+Analizador de secuencias ANSI basado en la maquina de estados VT500 (Paul Williams):
+	ground		texto imprimible y controles C0
+	escape		ESC [intermedios] final
+	csi			ESC [ [privado] parametros [intermedios] final
+	osc			ESC ] Ps ; Pt (BEL | ESC \)
+	dcs			ESC P parametros [intermedios] final datos ESC \
+	sos/pm/apc	ESC X | ESC ^ | ESC _ datos ESC \
+Los parametros admiten subparametros separados por ':' (38:2::R:G:B, 4:3).
+Las secuencias mal formadas se descartan sin cortar el texto que las rodea.
+Los bytes >= 0x80 se tratan como texto (UTF-8), no como controles C1.
+*/
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// TokenKind es el tipo de un Token
+type TokenKind int
+
+// Tipos de token
+const (
+	TokenPrint		TokenKind = iota	// texto imprimible (Token.Text)
+	TokenControl						// control C0: \n, \r, \b, \t, BEL... (Token.Control)
+	TokenSGR							// CSI ... m (Token.Params)
+	TokenCursor							// movimiento del cursor: CUU, CUD, CUF, CUB, CNL, CPL, CHA, CUP, HVP, VPA, HPA
+	TokenErase							// borrado: ED (J), EL (K), ECH (X)
+	TokenCSI							// otra secuencia CSI
+	TokenEscape							// ESC [intermedios] final (ESC 7, ESC 8, ESC c...)
+	TokenOSC							// ESC ] (Token.Command y Token.Payload)
+	TokenDCS							// ESC P (Token.Params, Token.Final y Token.Payload)
+	TokenString							// SOS, PM o APC (Token.Final es X, ^ o _; Token.Payload)
+)
+
+// MaxStringLength es el tamaño maximo que se guarda de los datos de OSC, DCS, SOS, PM y APC
+// El resto se consume sin guardar
+var MaxStringLength = 1 << 20
+
+// Limites de los parametros: los valores mayores se recortan y los parametros de mas se ignoran
+const (
+	maxParams		= 32
+	maxParamValue	= 65535
+)
+
+// Param es un parametro numerico con sus subparametros (separados por ':')
+// Un valor omitido vale -1 (ver Token.Param)
+type Param struct {
+	Value	int
+	Sub		[]int
+}
+
+// Token es un elemento de la salida ANSI: un texto, un control o una secuencia
+type Token struct {
+	Kind			TokenKind
+
+	// Texto imprimible (TokenPrint)
+	Text			string
+
+	// Byte de control (TokenControl)
+	Control			byte
+
+	// Marcador privado ('<', '=', '>' o '?'), intermedios y byte final de la secuencia
+	Private			byte
+	Intermediate	[]byte
+	Final			byte
+
+	// Parametros de CSI y DCS
+	Params			[]Param
+
+	// Numero de comando (OSC) y datos (OSC, DCS, SOS, PM y APC)
+	Command			int
+	Payload			[]byte
+
+	// Bytes originales del token
+	Raw				[]byte
+}
+
+// Param devuelve el valor del parametro i, o 'def' si no existe, se omitio o vale 0
+// (la convencion de CSI, donde 0 y omitido valen lo mismo)
+func (token Token) Param(i, def int) int {
+	if i >= len(token.Params) || token.Params[i].Value <= 0 { return def }
+	return token.Params[i].Value
+}
+
+// Estados de la maquina VT500
+type parserState uint8
+
+const (
+	stateGround parserState = iota
+	stateEscape
+	stateEscapeIntermediate
+	stateCSIEntry
+	stateCSIParam
+	stateCSIIntermediate
+	stateCSIIgnore
+	stateOSCString
+	stateDCSEntry
+	stateDCSParam
+	stateDCSIntermediate
+	stateDCSPassthrough
+	stateDCSIgnore
+	stateString
+)
+
+// Parser analiza una secuencia de bytes ANSI por partes: una secuencia o un caracter UTF-8
+// puede quedar cortado entre dos llamadas a Parse
+type Parser struct {
+	state		parserState
+
+	// Secuencia en curso: bytes originales, parametros, marcador privado e intermedios
+	seq			[]byte
+	params		[]Param
+	param		[]int
+	hasParam	bool
+	private		byte
+	inter		[]byte
+	final		byte
+
+	// Texto pendiente y datos de la cadena en curso (OSC, DCS, SOS, PM o APC)
+	text		[]byte
+	payload		[]byte
+	stringKind	TokenKind
+
+	// Cadena terminada por un ESC, que espera el '\' de su ST (ESC \) para entregarse
+	pending		*Token
+}
+
+// NewParser crea un analizador en el estado inicial
+func NewParser() *Parser {
+	return &Parser{}
+}
+
+// Tokenize analiza una salida completa y devuelve sus tokens
+func Tokenize(data []byte) []Token {
+	var tokens []Token
+
+	parser := NewParser()
+	emit := func(token Token) { tokens = append(tokens, token) }
+	parser.Parse(data, emit)
+	parser.Flush(emit)
+
+	return tokens
+}
+
+// Strip quita de un texto todas las secuencias de escape y los controles salvo \n y \t
+func Strip(text string) string {
+	var sb strings.Builder
+	parser := NewParser()
+	emit := func(token Token) {
+		switch token.Kind {
+		case TokenPrint:
+			sb.WriteString(token.Text)
+		case TokenControl:
+			if token.Control == '\n' || token.Control == '\t' { sb.WriteByte(token.Control) }
+		}
+	}
+	parser.Parse([]byte(text), emit)
+	parser.Flush(emit)
+
+	return sb.String()
+}
+
+// Parse analiza 'data' y llama a 'emit' con cada token completo
+// El texto se entrega al final de cada llamada (salvo un caracter UTF-8 incompleto),
+// asi que un mismo texto puede llegar en varios TokenPrint
+func (p *Parser) Parse(data []byte, emit func(Token)) {
+	for _, b := range data {
+		p.advance(b, emit)
+	}
+	p.flushText(emit, false)
+}
+
+// Flush entrega el texto pendiente, incluido un caracter UTF-8 incompleto
+// La secuencia en curso (si la hay) se conserva para la siguiente llamada a Parse
+func (p *Parser) Flush(emit func(Token)) {
+	p.emitPending(emit)
+	p.flushText(emit, true)
+}
+
+// flushText entrega el texto pendiente; si 'all' es falso conserva un caracter UTF-8 incompleto al final
+func (p *Parser) flushText(emit func(Token), all bool) {
+	if len(p.text) == 0 { return }
+
+	n := len(p.text)
+	if !all { n -= incompleteRuneTail(p.text) }
+	if n == 0 { return }
+
+	text := string(p.text[:n])
+	emit(Token{Kind: TokenPrint, Text: text, Raw: []byte(text)})
+	p.text = append(p.text[:0], p.text[n:]...)
+}
+
+// incompleteRuneTail devuelve cuantos bytes del final forman un caracter UTF-8 incompleto
+func incompleteRuneTail(text []byte) int {
+	for i := 1; i <= min(utf8.UTFMax - 1, len(text)); i++ {
+		b := text[len(text) - i]
+		if b < 0x80 { return 0 }
+		if !utf8.RuneStart(b) { continue }
+
+		if utf8.FullRune(text[len(text) - i:]) { return 0 }
+		return i
+	}
+	return 0
+}
+
+// advance aplica un byte a la maquina de estados
+func (p *Parser) advance(b byte, emit func(Token)) {
+	if p.pending != nil {
+		if b == '\\' {
+			// ST que termina la cadena: forma parte de sus bytes originales
+			p.pending.Raw = append(p.pending.Raw, 0x1b, b)
+			p.emitPending(emit)
+			p.state = stateGround
+			return
+		}
+		p.emitPending(emit)
+	}
+
+	// Transiciones desde cualquier estado
+	switch b {
+	case 0x1b:
+		// Un ESC dentro de una cadena es el comienzo de su ST (ESC \)
+		token, inString := p.stringToken()
+		p.flushText(emit, true)
+		p.begin(stateEscape, b)
+		if inString { p.pending = &token }
+		return
+	case 0x18, 0x1a:
+		// CAN y SUB cancelan la secuencia en curso
+		if token, inString := p.stringToken(); inString { emit(token) }
+		p.flushText(emit, true)
+		p.state = stateGround
+		emit(Token{Kind: TokenControl, Control: b, Raw: []byte{b}})
+		return
+	}
+
+	switch p.state {
+	case stateGround:
+		if b < 0x20 || b == 0x7f {
+			p.execute(b, emit)
+			return
+		}
+		p.text = append(p.text, b)
+
+	case stateEscape, stateEscapeIntermediate:
+		if b < 0x20 {
+			p.execute(b, emit)
+			return
+		}
+		if b == 0x7f { return }
+
+		p.seq = append(p.seq, b)
+		switch {
+		case b <= 0x2f:
+			p.inter = append(p.inter, b)
+			p.state = stateEscapeIntermediate
+		case p.state == stateEscapeIntermediate:
+			p.dispatchEscape(b, emit)
+		case b == '[':
+			p.state = stateCSIEntry
+		case b == ']':
+			p.startString(stateOSCString, TokenOSC)
+		case b == 'P':
+			p.state = stateDCSEntry
+		case b == 'X' || b == '^' || b == '_':
+			p.final = b
+			p.startString(stateString, TokenString)
+		default:
+			p.dispatchEscape(b, emit)
+		}
+
+	case stateCSIEntry, stateCSIParam, stateCSIIntermediate, stateCSIIgnore:
+		p.advanceCSI(b, emit)
+
+	case stateDCSEntry, stateDCSParam, stateDCSIntermediate, stateDCSIgnore:
+		p.advanceDCS(b)
+
+	case stateOSCString:
+		if b == 0x07 {
+			p.seq = append(p.seq, b)
+			token, _ := p.stringToken()
+			emit(token)
+			return
+		}
+		if b >= 0x20 { p.collectPayload(b) }
+
+	case stateDCSPassthrough, stateString:
+		if b != 0x7f { p.collectPayload(b) }
+	}
+}
+
+// begin empieza una nueva secuencia en el estado dado
+func (p *Parser) begin(state parserState, b byte) {
+	p.state = state
+	p.seq = append(p.seq[:0], b)
+	p.params = nil
+	p.param = p.param[:0]
+	p.hasParam = false
+	p.private = 0
+	p.inter = nil
+	p.final = 0
+}
+
+// execute entrega un control C0
+// Dentro de una secuencia CSI o ESC el control se ejecuta sin interrumpirla
+func (p *Parser) execute(b byte, emit func(Token)) {
+	if b == 0x7f { return }
+
+	if p.state == stateGround { p.flushText(emit, true) }
+	emit(Token{Kind: TokenControl, Control: b, Raw: []byte{b}})
+}
+
+// advanceCSI aplica un byte a una secuencia CSI
+func (p *Parser) advanceCSI(b byte, emit func(Token)) {
+	switch {
+	case b < 0x20:
+		p.execute(b, emit)
+		return
+	case b == 0x7f:
+		return
+	}
+
+	p.seq = append(p.seq, b)
+
+	switch {
+	case p.state == stateCSIIgnore:
+		if b >= 0x40 && b <= 0x7e { p.state = stateGround }
+
+	case b >= '0' && b <= ';':
+		if p.state == stateCSIIntermediate { p.state = stateCSIIgnore; return }
+		p.state = stateCSIParam
+		p.collectParam(b)
+
+	case b >= '<' && b <= '?':
+		// El marcador privado solo puede ir al principio
+		if p.state != stateCSIEntry { p.state = stateCSIIgnore; return }
+		p.private = b
+		p.state = stateCSIParam
+
+	case b <= 0x2f:
+		p.inter = append(p.inter, b)
+		p.state = stateCSIIntermediate
+
+	case b <= 0x7e:
+		p.finishParam()
+		p.final = b
+		p.dispatchCSI(emit)
+		p.state = stateGround
+
+	default:
+		// Bytes >= 0x80 no son validos en una secuencia
+		p.state = stateCSIIgnore
+	}
+}
+
+// advanceDCS aplica un byte a la cabecera de una secuencia DCS
+func (p *Parser) advanceDCS(b byte) {
+	if b < 0x20 || b == 0x7f { return }
+
+	p.seq = append(p.seq, b)
+
+	switch {
+	case p.state == stateDCSIgnore:
+		// Se consume hasta el ST
+
+	case b >= '0' && b <= ';':
+		if p.state == stateDCSIntermediate { p.state = stateDCSIgnore; return }
+		p.state = stateDCSParam
+		p.collectParam(b)
+
+	case b >= '<' && b <= '?':
+		if p.state != stateDCSEntry { p.state = stateDCSIgnore; return }
+		p.private = b
+		p.state = stateDCSParam
+
+	case b <= 0x2f:
+		p.inter = append(p.inter, b)
+		p.state = stateDCSIntermediate
+
+	case b <= 0x7e:
+		p.finishParam()
+		p.final = b
+		p.startString(stateDCSPassthrough, TokenDCS)
+
+	default:
+		p.state = stateDCSIgnore
+	}
+}
+
+// collectParam agrega un digito, ';' o ':' al parametro en curso
+func (p *Parser) collectParam(b byte) {
+	if !p.hasParam {
+		p.param = append(p.param[:0], -1)
+		p.hasParam = true
+	}
+
+	last := len(p.param) - 1
+	switch b {
+	case ';':
+		p.finishParam()
+		p.param = append(p.param[:0], -1)
+		p.hasParam = true
+	case ':':
+		if len(p.param) < maxParams { p.param = append(p.param, -1) }
+	default:
+		value := max(p.param[last], 0) * 10 + int(b - '0')
+		p.param[last] = min(value, maxParamValue)
+	}
+}
+
+// finishParam guarda el parametro en curso
+func (p *Parser) finishParam() {
+	if !p.hasParam { return }
+	p.hasParam = false
+
+	if len(p.params) >= maxParams { return }
+
+	param := Param{Value: p.param[0]}
+	if len(p.param) > 1 { param.Sub = append([]int(nil), p.param[1:]...) }
+	p.params = append(p.params, param)
+}
+
+// startString empieza una cadena (OSC, DCS, SOS, PM o APC)
+func (p *Parser) startString(state parserState, kind TokenKind) {
+	p.state = state
+	p.stringKind = kind
+	p.payload = p.payload[:0]
+}
+
+// collectPayload agrega un byte a los datos de la cadena en curso
+func (p *Parser) collectPayload(b byte) {
+	p.seq = append(p.seq, b)
+	if len(p.payload) < MaxStringLength { p.payload = append(p.payload, b) }
+}
+
+// emitPending entrega la cadena que esperaba el '\' de su ST
+func (p *Parser) emitPending(emit func(Token)) {
+	if p.pending == nil { return }
+
+	token := *p.pending
+	p.pending = nil
+	emit(token)
+}
+
+// stringToken termina la cadena en curso y la devuelve como token, si habia una
+func (p *Parser) stringToken() (Token, bool) {
+	switch p.state {
+	case stateOSCString, stateDCSPassthrough, stateString:
+	default:
+		return Token{}, false
+	}
+
+	token := Token{
+		Kind:			p.stringKind,
+		Private:		p.private,
+		Intermediate:	p.inter,
+		Final:			p.final,
+		Params:			p.params,
+		Payload:		bytes.Clone(p.payload),
+		Raw:			bytes.Clone(p.seq),
+	}
+
+	// OSC: "Ps ; Pt" -> Command = Ps, Payload = Pt
+	if token.Kind == TokenOSC {
+		number, rest, found := bytes.Cut(token.Payload, []byte{';'})
+		if command, err := strconv.Atoi(string(number)); err == nil {
+			token.Command = command
+			if found { token.Payload = rest } else { token.Payload = nil }
+		} else {
+			token.Command = -1
+		}
+	}
+
+	p.state = stateGround
+	return token, true
+}
+
+// dispatchEscape entrega una secuencia ESC [intermedios] final
+func (p *Parser) dispatchEscape(final byte, emit func(Token)) {
+	p.state = stateGround
+
+	emit(Token{
+		Kind:			TokenEscape,
+		Intermediate:	p.inter,
+		Final:			final,
+		Raw:			bytes.Clone(p.seq),
+	})
+}
+
+// dispatchCSI entrega una secuencia CSI clasificada segun su byte final
+func (p *Parser) dispatchCSI(emit func(Token)) {
+	token := Token{
+		Kind:			TokenCSI,
+		Private:		p.private,
+		Intermediate:	p.inter,
+		Final:			p.final,
+		Params:			p.params,
+		Raw:			bytes.Clone(p.seq),
+	}
+
+	if token.Private == 0 && len(token.Intermediate) == 0 {
+		switch token.Final {
+		case 'm':
+			token.Kind = TokenSGR
+		case 'A', 'B', 'C', 'D', 'E', 'F', 'G', 'H', 'f', 'd', '`', 'a', 'e':
+			token.Kind = TokenCursor
+		case 'J', 'K', 'X':
+			token.Kind = TokenErase
+		}
+	}
+
+	emit(token)
+}
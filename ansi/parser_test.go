@@ -0,0 +1,171 @@
+package ansi
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// parseChunks analiza los trozos con un mismo Parser (como si llegaran en varias lecturas),
+// termina con Flush y describe los tokens entregados
+func parseChunks(chunks ...string) []string {
+	var tokens []string
+
+	parser := NewParser()
+	emit := func(token Token) { tokens = append(tokens, describe(token)) }
+	for _, chunk := range chunks { parser.Parse([]byte(chunk), emit) }
+	parser.Flush(emit)
+
+	return tokens
+}
+
+// describe resume un token en una linea facil de comparar
+func describe(token Token) string {
+	switch token.Kind {
+	case TokenPrint:
+		return fmt.Sprintf("text %q", token.Text)
+	case TokenControl:
+		return fmt.Sprintf("ctl %#02x", token.Control)
+	case TokenEscape:
+		return fmt.Sprintf("esc %s%c", token.Intermediate, token.Final)
+	case TokenOSC:
+		return fmt.Sprintf("osc %d %q", token.Command, token.Payload)
+	case TokenDCS:
+		return fmt.Sprintf("dcs %s%s%c %q", describeParams(token), token.Intermediate, token.Final, token.Payload)
+	case TokenString:
+		return fmt.Sprintf("str %c %q", token.Final, token.Payload)
+	}
+
+	kind := map[TokenKind]string{TokenSGR: "sgr", TokenCursor: "cursor", TokenErase: "erase", TokenCSI: "csi"}[token.Kind]
+	return fmt.Sprintf("%s %s%s%c", kind, describeParams(token), token.Intermediate, token.Final)
+}
+
+// describeParams escribe los parametros como en la secuencia (-1 para los omitidos),
+// precedidos del marcador privado
+func describeParams(token Token) string {
+	var sb strings.Builder
+	if token.Private != 0 { sb.WriteByte(token.Private) }
+
+	for i, param := range token.Params {
+		if i > 0 { sb.WriteByte(';') }
+		fmt.Fprint(&sb, param.Value)
+		for _, sub := range param.Sub { fmt.Fprintf(&sb, ":%d", sub) }
+	}
+	return sb.String()
+}
+
+// paramList devuelve "1;2;...;n"
+func paramList(n int) string {
+	params := make([]string, n)
+	for i := range params { params[i] = fmt.Sprint(i + 1) }
+	return strings.Join(params, ";")
+}
+
+func TestParser(t *testing.T) {
+	tests := []struct {
+		name	string
+		chunks	[]string
+		want	[]string
+	}{
+		// CSI
+		{"sgr", []string{"a\x1b[1;31mb"}, []string{`text "a"`, "sgr 1;31m", `text "b"`}},
+		{"subparametros", []string{"\x1b[38:2::10:20:30m"}, []string{"sgr 38:2:-1:10:20:30m"}},
+		{"subparametros mezclados", []string{"\x1b[1;4:3;38;5;196m"}, []string{"sgr 1;4:3;38;5;196m"}},
+		{"parametros omitidos", []string{"\x1b[;5H"}, []string{"cursor -1;5H"}},
+		{"valor recortado", []string{"\x1b[99999999A"}, []string{"cursor 65535A"}},
+		{"parametros de mas", []string{"\x1b[" + paramList(maxParams + 8) + "m"}, []string{"sgr " + paramList(maxParams) + "m"}},
+		{"privado", []string{"\x1b[?1049h"}, []string{"csi ?1049h"}},
+		{"borrado", []string{"\x1b[2J\x1b[K"}, []string{"erase 2J", "erase K"}},
+		{"control dentro de CSI", []string{"\x1b[1\n2m"}, []string{"ctl 0x0a", "sgr 12m"}},
+		{"CSI mal formada", []string{"\x1b[1$2mtexto"}, []string{`text "texto"`}},
+		{"CAN cancela", []string{"\x1b[12\x18abc"}, []string{"ctl 0x18", `text "abc"`}},
+		{"CSI partida", []string{"\x1b[3", "8;5;2", "08m"}, []string{"sgr 38;5;208m"}},
+
+		// ESC
+		{"escape", []string{"\x1b7\x1b(B"}, []string{"esc 7", "esc (B"}},
+
+		// OSC terminada por BEL o por ST (ESC \)
+		{"OSC con BEL", []string{"\x1b]0;titulo\x07"}, []string{`osc 0 "titulo"`}},
+		{"OSC con ST", []string{"\x1b]11;rgb:1e1e/1e1e/2e2e\x1b\\x"}, []string{`osc 11 "rgb:1e1e/1e1e/2e2e"`, `text "x"`}},
+		{"OSC sin numero", []string{"\x1b]abc\x07"}, []string{`osc -1 "abc"`}},
+		{"OSC partida", []string{"\x1b]2;ho", "la\x07x"}, []string{`osc 2 "hola"`, `text "x"`}},
+		{"ST partido", []string{"\x1b]2;hola\x1b", "\\texto"}, []string{`osc 2 "hola"`, `text "texto"`}},
+		{"ST partido al final", []string{"\x1b]2;hola\x1b"}, []string{`osc 2 "hola"`}},
+		{"ESC que no es ST", []string{"\x1b]2;a\x1b", "[1m"}, []string{`osc 2 "a"`, "sgr 1m"}},
+
+		// DCS y SOS, PM, APC
+		{"DCS", []string{"\x1bP1$qm\x1b\\"}, []string{`dcs 1$q "m"`}},
+		{"DCS partida", []string{"\x1bP$q", "\"p\x1b", "\\ok"}, []string{`dcs $q "\"p"`, `text "ok"`}},
+		{"DCS ignorada", []string{"\x1bP1$2qdatos\x1b\\ok"}, []string{`esc \`, `text "ok"`}},
+		{"DCS ignorada con UTF-8", []string{"\x1bP1\xc3q", "mas datos\x1b\\ok"}, []string{`esc \`, `text "ok"`}},
+		{"APC", []string{"\x1b_Gf=100;AAAA\x1b\\"}, []string{`str _ "Gf=100;AAAA"`}},
+
+		// UTF-8 partido entre llamadas: el caracter no se corta
+		{"UTF-8 partido", []string{"a\xc3", "\xa9b"}, []string{`text "a"`, `text "éb"`}},
+		{"UTF-8 en tres partes", []string{"\xf0\x9f", "\x98", "\x80!"}, []string{`text "😀!"`}},
+		{"UTF-8 incompleto al final", []string{"a\xe2\x82"}, []string{`text "a"`, `text "\xe2\x82"`}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := parseChunks(test.chunks...)
+			if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+				t.Errorf("%q:\n\t%s\nse esperaba:\n\t%s", test.chunks, strings.Join(got, "\n\t"), strings.Join(test.want, "\n\t"))
+			}
+		})
+	}
+}
+
+// TestParserTextRunes comprueba que, partiendo la entrada en cualquier punto, ningun
+// TokenPrint corta un caracter UTF-8
+func TestParserTextRunes(t *testing.T) {
+	const input = "año ☃ 😀 界\x1b[1mñ"
+
+	for cut := range len(input) + 1 {
+		parser := NewParser()
+		var text strings.Builder
+		emit := func(token Token) {
+			if token.Kind != TokenPrint { return }
+			if !utf8.ValidString(token.Text) { t.Errorf("corte en %d: texto %q no es UTF-8 valido", cut, token.Text) }
+			text.WriteString(token.Text)
+		}
+		parser.Parse([]byte(input[:cut]), emit)
+		parser.Parse([]byte(input[cut:]), emit)
+		parser.Flush(emit)
+
+		if text.String() != "año ☃ 😀 界ñ" { t.Errorf("corte en %d: texto %q", cut, text.String()) }
+	}
+}
+
+// TestParserMaxStringLength comprueba que los datos que pasan de MaxStringLength se
+// consumen sin guardarse y que el texto siguiente no se pierde
+func TestParserMaxStringLength(t *testing.T) {
+	defer func(limit int) { MaxStringLength = limit }(MaxStringLength)
+	MaxStringLength = 8
+
+	tests := []struct {
+		input	string
+		want	[]string
+	}{
+		// El limite cuenta el numero de comando: "52;c;YWJj" -> "52;c;YWJ"
+		{"\x1b]52;c;YWJjZGVmZ2hp\x07ok", []string{`osc 52 "c;YWJ"`, `text "ok"`}},
+		{"\x1bPq#0;2;0;0;0#0!100~\x1b\\ok", []string{`dcs q "#0;2;0;0"`, `text "ok"`}},
+		{"\x1b_" + strings.Repeat("A", 100) + "\x1b\\ok", []string{`str _ "AAAAAAAA"`, `text "ok"`}},
+	}
+
+	for _, test := range tests {
+		tokens := Tokenize([]byte(test.input))
+
+		got := make([]string, len(tokens))
+		for i, token := range tokens { got[i] = describe(token) }
+		if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("%q:\n\t%s\nse esperaba:\n\t%s", test.input, strings.Join(got, "\n\t"), strings.Join(test.want, "\n\t"))
+		}
+
+		// Los bytes originales se conservan enteros aunque los datos se recorten
+		if raw := string(tokens[0].Raw); raw != test.input[:len(test.input) - len("ok")] {
+			t.Errorf("%q: Raw = %q", test.input, raw)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package ansi
+
+/*
+Consultas al terminal y sus respuestas (llegan por la entrada como ReportEvent):
+	CSI 6n			posicion del cursor		CSI fila ; columna R
+	OSC 10 ; ?		color del texto			OSC 10 ; rgb:RRRR/GGGG/BBBB ST
+	OSC 11 ; ?		color del fondo			OSC 11 ; rgb:RRRR/GGGG/BBBB ST
+	CSI 14t			tamaño de la ventana	CSI 4 ; alto ; ancho t		(pixeles)
+	CSI 16t			tamaño de la celda		CSI 6 ; alto ; ancho t		(pixeles)
+	CSI ? Pm $p		estado de un modo		CSI ? Pm ; estado $y		(DECRQM)
+	CSI c			atributos del equipo	CSI ? ... c					(DA1)
+Todos los terminales responden a DA1, por eso se envia despues de las demas consultas:
+si su respuesta llega antes que otra, esa consulta no esta soportada.
+*/
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// ModeState es el estado de un modo segun la respuesta a DECRQM
+type ModeState int
+
+// Estados de un modo (DECRPM)
+const (
+	ModeNotRecognized ModeState = iota
+	ModeSet
+	ModeReset
+	ModePermanentlySet
+	ModePermanentlyReset
+)
+
+// IsSet indica si el modo esta activo (de forma temporal o permanente)
+func (state ModeState) IsSet() bool {
+	return state == ModeSet || state == ModePermanentlySet
+}
+
+// RequestCursorPosition pide la posicion del cursor (DSR 6)
+func RequestCursorPosition() string {
+	return Esc + "6n"
+}
+
+// RequestForegroundColor pide el color por defecto del texto (OSC 10)
+func RequestForegroundColor() string {
+	return OSCSequence(10, "?")
+}
+
+// RequestBackgroundColor pide el color por defecto del fondo (OSC 11)
+func RequestBackgroundColor() string {
+	return OSCSequence(11, "?")
+}
+
+// RequestWindowPixels pide el tamaño en pixeles del area de texto de la ventana (XTWINOPS 14)
+func RequestWindowPixels() string {
+	return Esc + "14t"
+}
+
+// RequestCellPixels pide el tamaño en pixeles de una celda (XTWINOPS 16)
+func RequestCellPixels() string {
+	return Esc + "16t"
+}
+
+// RequestMode pide el estado de un modo privado, por ejemplo 1049 o MouseSGR (DECRQM)
+func RequestMode(mode int) string {
+	return Esc + fmt.Sprintf("?%d$p", mode)
+}
+
+// RequestDeviceAttributes pide los atributos primarios del terminal (DA1)
+func RequestDeviceAttributes() string {
+	return Esc + "c"
+}
+
+// IsDeviceAttributes indica si el token es la respuesta a RequestDeviceAttributes
+func IsDeviceAttributes(token Token) bool {
+	return token.Kind == TokenCSI && token.Private == '?' && token.Final == 'c'
+}
+
+// ParseCursorPosition lee la respuesta a RequestCursorPosition: columna y fila desde 1 (como MoveTo)
+func ParseCursorPosition(token Token) (position image.Point, ok bool) {
+	if token.Final != 'R' || token.Private != 0 || len(token.Params) != 2 { return image.Point{}, false }
+
+	return image.Pt(token.Param(1, 1), token.Param(0, 1)), true
+}
+
+// ParseColorReply lee la respuesta a RequestForegroundColor (command 10) o RequestBackgroundColor (11)
+func ParseColorReply(token Token, command int) (c color.RGBA, ok bool) {
+	if token.Kind != TokenOSC || token.Command != command { return color.RGBA{}, false }
+
+	c, err := ParseColor(string(token.Payload))
+	return c, err == nil
+}
+
+// ParsePixelsReply lee la respuesta a RequestWindowPixels (kind 4) o RequestCellPixels (kind 6):
+// ancho y alto en pixeles
+func ParsePixelsReply(token Token, kind int) (size image.Point, ok bool) {
+	if token.Final != 't' || token.Private != 0 || len(token.Params) < 3 || token.Param(0, 0) != kind {
+		return image.Point{}, false
+	}
+
+	size = image.Pt(token.Param(2, 0), token.Param(1, 0))
+	return size, size.X > 0 && size.Y > 0
+}
+
+// ParseModeReply lee la respuesta a RequestMode: el modo y su estado
+func ParseModeReply(token Token) (mode int, state ModeState, ok bool) {
+	if token.Final != 'y' || token.Private != '?' || string(token.Intermediate) != "$" || len(token.Params) != 2 {
+		return 0, ModeNotRecognized, false
+	}
+
+	state = ModeState(token.Param(1, 0))
+	if state > ModePermanentlyReset { state = ModeNotRecognized }
+
+	return token.Param(0, 0), state, true
+}
+
+// ParseColor convierte un color de X11 en RGBA: "rgb:R/G/B" (de 1 a 4 digitos hexadecimales
+// por canal), "rgba:R/G/B/A" o "#RGB", "#RRGGBB", "#RRRRGGGGBBBB"
+func ParseColor(spec string) (color.RGBA, error) {
+	invalid := fmt.Errorf("color no valido %q", spec)
+
+	var channels []string
+	switch {
+	case strings.HasPrefix(spec, "rgb:"):
+		channels = strings.Split(spec[4:], "/")
+		if len(channels) != 3 { return color.RGBA{}, invalid }
+	case strings.HasPrefix(spec, "rgba:"):
+		channels = strings.Split(spec[5:], "/")
+		if len(channels) != 4 { return color.RGBA{}, invalid }
+	case strings.HasPrefix(spec, "#") && len(spec) > 1 && (len(spec) - 1) % 3 == 0:
+		size := (len(spec) - 1) / 3
+		for i := range 3 { channels = append(channels, spec[1 + i * size : 1 + (i + 1) * size]) }
+	default:
+		return color.RGBA{}, invalid
+	}
+
+	values := [4]uint8{255, 255, 255, 255}
+	for i, channel := range channels {
+		if len(channel) == 0 || len(channel) > 4 { return color.RGBA{}, invalid }
+
+		value, err := strconv.ParseUint(channel, 16, 16)
+		if err != nil { return color.RGBA{}, invalid }
+
+		// Escala de 1..4 digitos a 8 bits: "f" -> 255, "ffff" -> 255, "80" -> 128
+		maximum := uint64(1) << (4 * len(channel)) - 1
+		values[i] = uint8((value * 255 + maximum / 2) / maximum)
+	}
+
+	return color.RGBA{values[0], values[1], values[2], values[3]}, nil
+}
@@ -0,0 +1,46 @@
+package ansi
+
+/*
+Region de desplazamiento (DECSTBM): las filas entre 'top' y 'bottom' (desde 1, inclusivas)
+se desplazan solas; el resto de la pantalla queda fija. Fijar o quitar la region mueve el
+cursor a (1,1). Dentro de la region:
+	CSI n S		desplaza el contenido n filas hacia arriba (SU) y deja filas vacias abajo
+	CSI n T		desplaza el contenido n filas hacia abajo (SD) y deja filas vacias arriba
+	CSI n L		inserta n filas vacias en la fila del cursor (IL), desplazando las de debajo
+	CSI n M		borra n filas desde la del cursor (DL), subiendo las de debajo
+Las filas vacias usan el color de fondo actual, por lo que conviene resetear los colores antes.
+*/
+
+import (
+	"fmt"
+)
+
+// SetScrollRegion limita el desplazamiento a las filas de 'top' a 'bottom' (desde 1, inclusivas)
+func SetScrollRegion(top, bottom int) string {
+	return Esc + fmt.Sprintf("%d;%dr", top, bottom)
+}
+
+// ResetScrollRegion vuelve a desplazar la pantalla completa
+func ResetScrollRegion() string {
+	return Esc + "r"
+}
+
+// ScrollUp desplaza el contenido de la region un numero de filas hacia arriba
+func ScrollUp(lines int) string {
+	return Esc + fmt.Sprintf("%dS", lines)
+}
+
+// ScrollDown desplaza el contenido de la region un numero de filas hacia abajo
+func ScrollDown(lines int) string {
+	return Esc + fmt.Sprintf("%dT", lines)
+}
+
+// InsertLines inserta filas vacias en la fila del cursor, bajando las siguientes dentro de la region
+func InsertLines(lines int) string {
+	return Esc + fmt.Sprintf("%dL", lines)
+}
+
+// DeleteLines borra filas desde la del cursor, subiendo las siguientes dentro de la region
+func DeleteLines(lines int) string {
+	return Esc + fmt.Sprintf("%dM", lines)
+}
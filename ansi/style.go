@@ -0,0 +1,319 @@
+package ansi
+
+/*
+Atributos SGR (Select Graphic Rendition) ademas de los colores:
+	1 negrita	2 tenue		3 cursiva	4 subrayado		5 parpadeo		6 parpadeo rapido
+	7 inverso	8 oculto	9 tachado	53 sobrelinea
+	22 quita negrita y tenue, 23 cursiva, 24 subrayado, 25 parpadeo, 27 inverso,
+	28 oculto, 29 tachado, 55 sobrelinea
+	4:0 .. 4:5 estilo del subrayado (ninguno, simple, doble, ondulado, punteado, discontinuo)
+	58;2;R;G;B color del subrayado, 59 lo quita
+*/
+
+import (
+	"image/color"
+	"unicode/utf8"
+)
+
+// Attr es un conjunto de atributos de texto SGR
+type Attr uint16
+
+// Atributos de texto
+const (
+	Bold Attr = 1 << iota
+	Dim
+	Italic
+	Blink
+	RapidBlink
+	Reverse
+	Hidden
+	Strikethrough
+	Overline
+)
+
+// UnderlineStyle es el estilo del subrayado (parametro 4:x)
+type UnderlineStyle uint8
+
+// Estilos de subrayado
+const (
+	UnderlineNone UnderlineStyle = iota
+	UnderlineSingle
+	UnderlineDouble
+	UnderlineCurly
+	UnderlineDotted
+	UnderlineDashed
+)
+
+// attrCodes son los codigos para activar y desactivar cada atributo, en el orden de Attr
+var attrCodes = [...]struct{ on, off byte }{
+	{1, 22}, {2, 22}, {3, 23}, {5, 25}, {6, 25}, {7, 27}, {8, 28}, {9, 29}, {53, 55},
+}
+
+// Style combina colores y atributos de texto
+// Un color con alfa menor o igual a ALPHA_1 (como DefaultColor) es el color por defecto del terminal,
+// igual que en PaintBase
+type Style struct {
+	Foreground		color.RGBA
+	Background		color.RGBA
+	UnderlineColor	color.RGBA
+	Attrs			Attr
+	Underline		UnderlineStyle
+}
+
+// IsDefault indica si el estilo es el del terminal sin modificar (el que deja "\033[0m")
+func (s Style) IsDefault() bool {
+	return s.normalize() == Style{}
+}
+
+// normalize deja en cero los colores por defecto para poder comparar estilos
+func (s Style) normalize() Style {
+	if s.Foreground.A <= ALPHA_1		{ s.Foreground = color.RGBA{} }
+	if s.Background.A <= ALPHA_1		{ s.Background = color.RGBA{} }
+	if s.UnderlineColor.A <= ALPHA_1	{ s.UnderlineColor = color.RGBA{} }
+	return s
+}
+
+// SGR devuelve la secuencia que aplica el estilo partiendo del estilo por defecto
+func (s Style) SGR() string {
+	return Transition(Style{}, s)
+}
+
+// Transition devuelve la secuencia SGR mas corta para pasar del estilo 'from' al estilo 'to'
+// Compara los cambios uno a uno con reiniciar (0) y aplicar 'to' completo; si no hay cambios
+// devuelve una cadena vacia
+func Transition(from, to Style) string {
+	var buf []byte
+	AppendTransition(&buf, from, to)
+	return string(buf)
+}
+
+// AppendTransition agrega al búfer la secuencia de Transition
+func AppendTransition(buf *[]byte, from, to Style) {
+	from, to = from.normalize(), to.normalize()
+	if from == to { return }
+
+	diff := make([]byte, 0, 64)
+	appendStyleParams(&diff, from, to)
+
+	reset := make([]byte, 0, 64)
+	reset = append(reset, '0')
+	appendStyleParams(&reset, Style{}, to)
+
+	params := diff
+	if len(reset) < len(diff) { params = reset }
+
+	*buf = append(*buf, Esc...)
+	*buf = append(*buf, params...)
+	*buf = append(*buf, 'm')
+}
+
+// appendStyleParams agrega los parametros (separados por ';') que cambian 'from' por 'to'
+func appendStyleParams(buf *[]byte, from, to Style) {
+	// 22 y 25 quitan dos atributos a la vez: se vuelve a activar el que deba quedar
+	removed := from.Attrs &^ to.Attrs
+	added := to.Attrs &^ from.Attrs
+	if removed & (Bold | Dim) != 0		{ added |= to.Attrs & (Bold | Dim) }
+	if removed & (Blink | RapidBlink) != 0	{ added |= to.Attrs & (Blink | RapidBlink) }
+
+	var offDone Attr
+	for i, codes := range attrCodes {
+		attr := Attr(1) << i
+		if removed & attr == 0 { continue }
+
+		// Un solo 22 (o 25) para la pareja de atributos
+		if attr & (Dim | RapidBlink) != 0 && offDone & (attr >> 1) != 0 { continue }
+		offDone |= attr
+		appendParam(buf, codes.off)
+	}
+	for i, codes := range attrCodes {
+		if added & (Attr(1) << i) != 0 { appendParam(buf, codes.on) }
+	}
+
+	if from.Underline != to.Underline {
+		switch to.Underline {
+		case UnderlineNone:		appendParam(buf, 24)
+		case UnderlineSingle:	appendParam(buf, 4)
+		default:
+			appendParam(buf, 4)
+			*buf = append(*buf, ':', '0' + byte(to.Underline))
+		}
+	}
+
+	appendColorParam(buf, from.Foreground, to.Foreground, 38, 39)
+	appendColorParam(buf, from.Background, to.Background, 48, 49)
+	appendColorParam(buf, from.UnderlineColor, to.UnderlineColor, 58, 59)
+}
+
+// appendColorParam agrega el color 'to' (code;2;R;G;B) o el codigo que lo quita si cambio
+func appendColorParam(buf *[]byte, from, to color.RGBA, code, reset byte) {
+	if from == to { return }
+
+	if to.A == 0 {
+		appendParam(buf, reset)
+		return
+	}
+
+	appendParam(buf, code)
+	*buf = append(*buf, ";2"...)
+	for _, value := range [3]uint8{to.R, to.G, to.B} {
+		*buf = append(*buf, ';')
+		*buf = append(*buf, digitLookup[value]...)
+	}
+}
+
+// appendParam agrega un parametro numerico, separado del anterior por ';'
+func appendParam(buf *[]byte, value byte) {
+	if len(*buf) > 0 { *buf = append(*buf, ';') }
+	*buf = append(*buf, digitLookup[value]...)
+}
+
+// PaintString genera una cadena de texto con el estilo.
+// Si resetStyle es true, agrega la secuencia que vuelve al estilo por defecto al final.
+func (s Style) PaintString(text string, resetStyle bool) string {
+	buf := make([]byte, 0, 64+len(text))
+	s.AppendPaintString(&buf, text, resetStyle)
+
+	return string(buf)
+}
+
+// AppendPaintString agrega al búfer una cadena de texto con el estilo.
+// Si resetStyle es true, agrega la secuencia que vuelve al estilo por defecto al final.
+func (s Style) AppendPaintString(buf *[]byte, text string, resetStyle bool) {
+	AppendTransition(buf, Style{}, s)
+	*buf = append(*buf, text...)
+
+	if resetStyle { AppendTransition(buf, s, Style{}) }
+}
+
+// PaintRune genera un carácter con el estilo.
+// Si resetStyle es true, agrega la secuencia que vuelve al estilo por defecto al final.
+func (s Style) PaintRune(character rune, resetStyle bool) []byte {
+	buf := make([]byte, 0, 64)
+	s.AppendPaintRune(&buf, character, resetStyle)
+
+	return buf
+}
+
+// AppendPaintRune agrega al búfer un carácter con el estilo.
+// Si resetStyle es true, agrega la secuencia que vuelve al estilo por defecto al final.
+func (s Style) AppendPaintRune(buf *[]byte, character rune, resetStyle bool) {
+	AppendTransition(buf, Style{}, s)
+	*buf = utf8.AppendRune(*buf, character)
+
+	if resetStyle { AppendTransition(buf, s, Style{}) }
+}
+
+// ansiColors son los 16 colores basicos (paleta por defecto de xterm)
+var ansiColors = [16]color.RGBA{
+	{0, 0, 0, 255}, {205, 0, 0, 255}, {0, 205, 0, 255}, {205, 205, 0, 255},
+	{0, 0, 238, 255}, {205, 0, 205, 255}, {0, 205, 205, 255}, {229, 229, 229, 255},
+	{127, 127, 127, 255}, {255, 0, 0, 255}, {0, 255, 0, 255}, {255, 255, 0, 255},
+	{92, 92, 255, 255}, {255, 0, 255, 255}, {0, 255, 255, 255}, {255, 255, 255, 255},
+}
+
+// PaletteColor devuelve el color 'index' de la paleta de 256 colores de xterm
+// (16 basicos, cubo de 6x6x6 y 24 grises)
+func PaletteColor(index uint8) color.RGBA {
+	switch {
+	case index < 16:
+		return ansiColors[index]
+	case index < 232:
+		levels := [6]uint8{0, 95, 135, 175, 215, 255}
+		index -= 16
+		return color.RGBA{levels[index / 36], levels[index / 6 % 6], levels[index % 6], 255}
+	default:
+		gray := 8 + (index - 232) * 10
+		return color.RGBA{gray, gray, gray, 255}
+	}
+}
+
+// ApplySGR devuelve el estilo que resulta de aplicar los parametros de una secuencia SGR
+// (ver Token.Params); admite colores de 16, 256 y 24 bits con ';' o con ':'
+func (s Style) ApplySGR(params []Param) Style {
+	if len(params) == 0 { return Style{} }
+
+	for i := 0; i < len(params); i++ {
+		param := params[i]
+
+		switch code := max(param.Value, 0); {
+		case code == 0:
+			s = Style{}
+		case code == 4:
+			s.Underline = UnderlineSingle
+			if len(param.Sub) > 0 && param.Sub[0] >= 0 && param.Sub[0] <= int(UnderlineDashed) {
+				s.Underline = UnderlineStyle(param.Sub[0])
+			}
+		case code == 21:
+			s.Underline = UnderlineDouble
+		case code == 24:
+			s.Underline = UnderlineNone
+		case code == 22:
+			s.Attrs &^= Bold | Dim
+		case code == 25:
+			s.Attrs &^= Blink | RapidBlink
+		case code >= 30 && code <= 37:
+			s.Foreground = ansiColors[code - 30]
+		case code >= 90 && code <= 97:
+			s.Foreground = ansiColors[code - 90 + 8]
+		case code >= 40 && code <= 47:
+			s.Background = ansiColors[code - 40]
+		case code >= 100 && code <= 107:
+			s.Background = ansiColors[code - 100 + 8]
+		case code == 39:
+			s.Foreground = color.RGBA{}
+		case code == 49:
+			s.Background = color.RGBA{}
+		case code == 59:
+			s.UnderlineColor = color.RGBA{}
+		case code == 38 || code == 48 || code == 58:
+			var extended color.RGBA
+			extended, i = extendedColor(params, i)
+
+			switch code {
+			case 38: s.Foreground = extended
+			case 48: s.Background = extended
+			case 58: s.UnderlineColor = extended
+			}
+		default:
+			for bit, codes := range attrCodes {
+				attr := Attr(1) << bit
+				if code == int(codes.on) { s.Attrs |= attr }
+				if code == int(codes.off) { s.Attrs &^= attr }
+			}
+		}
+	}
+
+	return s
+}
+
+// extendedColor lee un color 38/48/58 que empieza en params[i] y devuelve el indice del ultimo
+// parametro usado. Con ':' el color va en los subparametros (38:2::R:G:B o 38:2:R:G:B, 38:5:N);
+// con ';' en los parametros siguientes (38;2;R;G;B, 38;5;N)
+func extendedColor(params []Param, i int) (color.RGBA, int) {
+	values := params[i].Sub
+	last := i
+
+	if len(values) == 0 {
+		for _, next := range params[i+1:] { values = append(values, next.Value) }
+		switch {
+		case len(values) >= 4 && values[0] == 2: last = i + 4
+		case len(values) >= 2 && values[0] == 5: last = i + 2
+		default: return color.RGBA{}, len(params) - 1
+		}
+	} else if len(values) == 5 && values[0] == 2 {
+		// 38:2:<espacio de color>:R:G:B
+		values = append(values[:1:1], values[2:]...)
+	} else if len(values) > 5 && values[0] == 2 {
+		values = append(values[:1:1], values[2:5]...)
+	}
+
+	channel := func(value int) uint8 { return uint8(min(max(value, 0), 255)) }
+
+	switch {
+	case len(values) >= 4 && values[0] == 2:
+		return color.RGBA{channel(values[1]), channel(values[2]), channel(values[3]), 255}, last
+	case len(values) >= 2 && values[0] == 5:
+		return PaletteColor(channel(values[1])), last
+	}
+	return color.RGBA{}, last
+}
@@ -0,0 +1,297 @@
+package ansi
+
+/*
+Ancho en columnas del texto en el terminal:
+	0	controles, marcas combinantes, formato (ZWJ, selectores de variacion) y jamos mediales
+	2	caracteres anchos y de ancho completo de Asia oriental (East Asian Width W y F) y emojis
+	1	el resto
+Un grafema (caracter con sus marcas, secuencia de emojis unida con ZWJ, bandera de dos
+indicadores regionales...) ocupa el ancho de su primer caracter, o 2 si lleva el selector
+de presentacion emoji (U+FE0F). Las secuencias de escape no ocupan columnas.
+*/
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Alignment es la alineacion del texto dentro de un ancho (ver Pad)
+type Alignment int
+
+// Alineaciones
+const (
+	AlignLeft Alignment = iota
+	AlignCenter
+	AlignRight
+)
+
+// Ellipsis es la marca que usa Pad al recortar un texto
+const Ellipsis = "…"
+
+// Caracteres especiales de los grafemas
+const (
+	zeroWidthJoiner		= '\u200d'
+	emojiPresentation	= '\ufe0f'
+)
+
+// wideRanges son los intervalos (inclusivos y ordenados) de ancho 2
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F}, {0x231A, 0x231B}, {0x2329, 0x232A}, {0x23E9, 0x23EC}, {0x23F0, 0x23F0},
+	{0x23F3, 0x23F3}, {0x25FD, 0x25FE}, {0x2614, 0x2615}, {0x2648, 0x2653}, {0x267F, 0x267F},
+	{0x2693, 0x2693}, {0x26A1, 0x26A1}, {0x26AA, 0x26AB}, {0x26BD, 0x26BE}, {0x26C4, 0x26C5},
+	{0x26CE, 0x26CE}, {0x26D4, 0x26D4}, {0x26EA, 0x26EA}, {0x26F2, 0x26F3}, {0x26F5, 0x26F5},
+	{0x26FA, 0x26FA}, {0x26FD, 0x26FD}, {0x2705, 0x2705}, {0x270A, 0x270B}, {0x2728, 0x2728},
+	{0x274C, 0x274C}, {0x274E, 0x274E}, {0x2753, 0x2755}, {0x2757, 0x2757}, {0x2795, 0x2797},
+	{0x27B0, 0x27B0}, {0x27BF, 0x27BF}, {0x2B1B, 0x2B1C}, {0x2B50, 0x2B50}, {0x2B55, 0x2B55},
+	{0x2E80, 0x303E}, {0x3041, 0x33FF}, {0x3400, 0x4DBF}, {0x4E00, 0x9FFF}, {0xA000, 0xA4CF},
+	{0xA960, 0xA97F}, {0xAC00, 0xD7A3}, {0xF900, 0xFAFF}, {0xFE10, 0xFE19}, {0xFE30, 0xFE6F},
+	{0xFF00, 0xFF60}, {0xFFE0, 0xFFE6}, {0x16FE0, 0x16FE4}, {0x17000, 0x18CFF}, {0x1B000, 0x1B2FF},
+	{0x1F004, 0x1F004}, {0x1F0CF, 0x1F0CF}, {0x1F18E, 0x1F18E}, {0x1F191, 0x1F19A}, {0x1F1E6, 0x1F1FF},
+	{0x1F200, 0x1F251}, {0x1F300, 0x1F320}, {0x1F32D, 0x1F335}, {0x1F337, 0x1F37C}, {0x1F37E, 0x1F393},
+	{0x1F3A0, 0x1F3CA}, {0x1F3CF, 0x1F3D3}, {0x1F3E0, 0x1F3F0}, {0x1F3F4, 0x1F3F4}, {0x1F3F8, 0x1F43E},
+	{0x1F440, 0x1F440}, {0x1F442, 0x1F4FC}, {0x1F4FF, 0x1F53D}, {0x1F54B, 0x1F54E}, {0x1F550, 0x1F567},
+	{0x1F57A, 0x1F57A}, {0x1F595, 0x1F596}, {0x1F5A4, 0x1F5A4}, {0x1F5FB, 0x1F64F}, {0x1F680, 0x1F6C5},
+	{0x1F6CC, 0x1F6CC}, {0x1F6D0, 0x1F6D2}, {0x1F6D5, 0x1F6D7}, {0x1F6DC, 0x1F6DF}, {0x1F6EB, 0x1F6EC},
+	{0x1F6F4, 0x1F6FC}, {0x1F7E0, 0x1F7EB}, {0x1F7F0, 0x1F7F0}, {0x1F90C, 0x1F93A}, {0x1F93C, 0x1F945},
+	{0x1F947, 0x1F9FF}, {0x1FA70, 0x1FAFF}, {0x20000, 0x2FFFD}, {0x30000, 0x3FFFD},
+}
+
+// RuneWidth devuelve las columnas que ocupa un caracter suelto
+func RuneWidth(r rune) int {
+	switch {
+	case r < 0x20 || (r >= 0x7f && r < 0xa0):
+		return 0
+	case r < 0x1100:
+		if unicode.In(r, unicode.Mn, unicode.Me) { return 0 }
+		return 1
+	case isZeroWidth(r):
+		return 0
+	case isWide(r):
+		return 2
+	}
+	return 1
+}
+
+// isZeroWidth indica si el caracter no ocupa columnas (marcas, formato y jamos mediales y finales)
+func isZeroWidth(r rune) bool {
+	return (r >= 0x1160 && r <= 0x11FF) || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf)
+}
+
+// isWide indica si el caracter ocupa dos columnas
+func isWide(r rune) bool {
+	_, found := slices.BinarySearchFunc(wideRanges, r, func(span [2]rune, r rune) int {
+		if r < span[0] { return 1 }
+		if r > span[1] { return -1 }
+		return 0
+	})
+	return found
+}
+
+// isRegionalIndicator indica si el caracter es una letra de bandera (dos forman una bandera)
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+// extendsGrapheme indica si el caracter se une al anterior en el mismo grafema
+// (marcas, formato, modificadores de tono de piel y etiquetas de las banderas)
+func extendsGrapheme(r rune) bool {
+	return isZeroWidth(r) || unicode.Is(unicode.Mc, r) ||
+		(r >= 0x1F3FB && r <= 0x1F3FF) || (r >= 0xE0020 && r <= 0xE007F)
+}
+
+// nextGrapheme devuelve el tamaño en bytes y el ancho del primer grafema del texto
+func nextGrapheme(text string) (size, width int) {
+	first, size := utf8.DecodeRuneInString(text)
+	width = RuneWidth(first)
+	if first == '\r' && strings.HasPrefix(text[size:], "\n") { return 2, 0 }
+	if width == 0 && first < 0xa0 { return size, 0 }
+
+	previous := first
+	for size < len(text) {
+		r, n := utf8.DecodeRuneInString(text[size:])
+
+		switch {
+		case previous == zeroWidthJoiner:
+		case isRegionalIndicator(first) && isRegionalIndicator(r) && size == utf8.RuneLen(first):
+		case extendsGrapheme(r):
+			if r == emojiPresentation && width == 1 { width = 2 }
+		default:
+			return size, width
+		}
+
+		size += n
+		previous = r
+	}
+	return size, width
+}
+
+// segment es una parte de un texto: un grafema o un token que no se imprime (escape o control)
+type segment struct {
+	text	string
+	width	int
+	token	*Token
+}
+
+// segments divide un texto en grafemas y secuencias de escape
+func segments(text string) []segment {
+	var parts []segment
+	for _, token := range Tokenize([]byte(text)) {
+		if token.Kind != TokenPrint {
+			parts = append(parts, segment{text: string(token.Raw), token: &token})
+			continue
+		}
+
+		for rest := token.Text; rest != ""; {
+			size, width := nextGrapheme(rest)
+			parts = append(parts, segment{text: rest[:size], width: width})
+			rest = rest[size:]
+		}
+	}
+	return parts
+}
+
+// StringWidth devuelve las columnas que ocupa el texto en el terminal, sin contar
+// las secuencias de escape ni los controles
+func StringWidth(text string) int {
+	width := 0
+	for _, part := range segments(text) { width += part.width }
+	return width
+}
+
+// Truncate recorta el texto a 'width' columnas terminando en 'tail' (por ejemplo Ellipsis)
+// si no cabe; un grafema ancho nunca queda partido
+// Las secuencias de escape posteriores al corte se conservan, asi los estilos y enlaces
+// que el texto cierra al final siguen cerrados
+func Truncate(text string, width int, tail string) string {
+	if StringWidth(text) <= width { return text }
+
+	limit := width - StringWidth(tail)
+	if limit < 0 { tail, limit = "", max(width, 0) }
+
+	var sb strings.Builder
+	used, cut := 0, false
+	for _, part := range segments(text) {
+		if part.token != nil {
+			sb.WriteString(part.text)
+			continue
+		}
+		if cut { continue }
+
+		if used + part.width > limit {
+			sb.WriteString(tail)
+			cut = true
+			continue
+		}
+		sb.WriteString(part.text)
+		used += part.width
+	}
+
+	return sb.String()
+}
+
+// Pad alinea el texto en 'width' columnas rellenando con espacios; si no cabe lo recorta
+// con Ellipsis
+func Pad(text string, width int, align Alignment) string {
+	text = Truncate(text, width, Ellipsis)
+	gap := max(width - StringWidth(text), 0)
+
+	left := 0
+	switch align {
+	case AlignCenter:	left = gap / 2
+	case AlignRight:	left = gap
+	}
+
+	return strings.Repeat(" ", left) + text + strings.Repeat(" ", gap - left)
+}
+
+// Wrap divide el texto en lineas de como maximo 'width' columnas, cortando entre palabras
+// (una palabra mas larga que la linea se parte) y en cada '\n'
+// El estilo SGR activo en un corte se cierra al final de la linea y se vuelve a abrir
+// al principio de la siguiente (con las mismas secuencias), asi cada linea se puede
+// dibujar por separado
+func Wrap(text string, width int) []string {
+	w := wrapper{limit: max(width, 1)}
+
+	for _, part := range segments(text) {
+		switch {
+		case part.token != nil && part.token.Kind == TokenControl && part.token.Control == '\n':
+			w.flushWord()
+			w.newLine(false)
+		case part.token == nil && part.text == " ":
+			w.flushWord()
+			if w.wrapped && w.width == 0 { continue }
+			w.spaces += part.text
+		default:
+			w.word = append(w.word, part)
+		}
+	}
+	w.flushWord()
+	w.lines = append(w.lines, w.line.String())
+
+	return w.lines
+}
+
+// wrapper es el estado de Wrap: la linea en curso, los espacios y la palabra pendientes
+type wrapper struct {
+	limit	int
+	lines	[]string
+	line	strings.Builder
+	width	int
+
+	// Estilo activo y secuencias SGR que lo forman desde el ultimo reinicio
+	style	Style
+	sgr		[]string
+
+	// Espacios y palabra todavia sin escribir en la linea
+	spaces	string
+	word	[]segment
+
+	// La linea en curso empezo por un corte (sus espacios iniciales se descartan)
+	wrapped	bool
+}
+
+// flushWord escribe la palabra pendiente, pasando a otra linea si no cabe
+func (w *wrapper) flushWord() {
+	if len(w.word) == 0 { return }
+
+	wordWidth := 0
+	for _, part := range w.word { wordWidth += part.width }
+
+	if w.width > 0 && w.width + len(w.spaces) + wordWidth > w.limit {
+		w.newLine(true)
+	} else {
+		w.line.WriteString(w.spaces)
+		w.width += len(w.spaces)
+	}
+	w.spaces = ""
+
+	for _, part := range w.word {
+		if part.width > 0 && w.width > 0 && w.width + part.width > w.limit { w.newLine(true) }
+
+		w.line.WriteString(part.text)
+		w.width += part.width
+		if part.token != nil && part.token.Kind == TokenSGR { w.applySGR(part) }
+	}
+	w.word = w.word[:0]
+}
+
+// newLine termina la linea en curso (cerrando el estilo activo) y empieza otra con el mismo estilo
+// 'wrapped' indica si es un corte de Wrap y no un '\n' del texto
+func (w *wrapper) newLine(wrapped bool) {
+	if !w.style.IsDefault() { w.line.WriteString(Esc + "0m") }
+	w.lines = append(w.lines, w.line.String())
+
+	w.line.Reset()
+	for _, sequence := range w.sgr { w.line.WriteString(sequence) }
+	w.width, w.spaces, w.wrapped = 0, "", wrapped
+}
+
+// applySGR actualiza el estilo activo con una secuencia SGR ya escrita en la linea
+func (w *wrapper) applySGR(part segment) {
+	w.style = w.style.ApplySGR(part.token.Params)
+	w.sgr = append(w.sgr, part.text)
+
+	if w.style.IsDefault() { w.sgr = w.sgr[:0] }
+}
@@ -0,0 +1,157 @@
+package ansi
+
+import (
+	"strings"
+	"testing"
+)
+
+// Grafemas de varios caracteres usados en las pruebas
+const (
+	family		= "\U0001F468\u200d\U0001F469\u200d\U0001F467"	// ZWJ
+	flagES		= "\U0001F1EA\U0001F1F8"	// dos indicadores regionales
+	flagFR		= "\U0001F1EB\U0001F1F7"
+	scotland	= "\U0001F3F4\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074\U000E007F"	// etiquetas
+	smiley		= "\u263a\ufe0f"	// VS16 sobre un caracter de ancho 1
+	thumbsUp	= "\U0001F44D\U0001F3FD"	// modificador de tono de piel
+)
+
+func TestNextGrapheme(t *testing.T) {
+	tests := []struct {
+		text	string
+		size	int
+		width	int
+	}{
+		{"ab", 1, 1},
+		{"e\u0301x", 3, 1},
+		{"界x", 3, 2},
+		{"\r\nx", 2, 0},
+		{"\tx", 1, 0},
+		{family + "x", len(family), 2},
+		{family + "\u200d", len(family) + 3, 2},
+		{flagES + flagFR, len(flagES), 2},
+		{"\U0001F1EAx", 4, 2},
+		{scotland + "x", len(scotland), 2},
+		{smiley + "x", len(smiley), 2},
+		{"\u263ax", 3, 1},
+		{"\u2764\ufe0f", 6, 2},
+		{thumbsUp + "x", len(thumbsUp), 2},
+		{"\u1100\u1161\u11a8x", 9, 2},
+	}
+
+	for _, test := range tests {
+		size, width := nextGrapheme(test.text)
+		if size != test.size || width != test.width {
+			t.Errorf("nextGrapheme(%q) = %d, %d; se esperaba %d, %d", test.text, size, width, test.size, test.width)
+		}
+	}
+}
+
+func TestStringWidth(t *testing.T) {
+	tests := []struct {
+		text	string
+		width	int
+	}{
+		{"", 0},
+		{"hola", 4},
+		{"año", 3},
+		{"日本語", 6},
+		{"\x1b[1;31mrojo\x1b[0m", 4},
+		{"\x1b]8;;https://example.com\x1b\\enlace\x1b]8;;\x1b\\", 6},
+		{family + flagES + flagFR + smiley, 8},
+		{"a\tb\n", 2},
+	}
+
+	for _, test := range tests {
+		if got := StringWidth(test.text); got != test.width { t.Errorf("StringWidth(%q) = %d, se esperaba %d", test.text, got, test.width) }
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name	string
+		text	string
+		width	int
+		tail	string
+		want	string
+	}{
+		{"cabe", "hola", 4, Ellipsis, "hola"},
+		{"ascii", "hola mundo", 6, Ellipsis, "hola …"},
+		{"sin cola", "hola mundo", 6, "", "hola m"},
+
+		// Un caracter ancho que no cabe entero se quita
+		{"CJK en el limite", "ab界c", 3, "", "ab"},
+		{"CJK en el limite con cola", "ab界c", 4, Ellipsis, "ab…"},
+		{"CJK", "界界界", 5, Ellipsis, "界界…"},
+		{"CJK con hueco", "界界界", 4, Ellipsis, "界…"},
+
+		// Los grafemas de varios caracteres no se parten
+		{"ZWJ", family + "ok", 3, Ellipsis, family + "…"},
+		{"ZWJ que no cabe", "a" + family + "b", 2, "", "a"},
+		{"bandera", "ab" + flagES + flagFR, 5, "", "ab" + flagES},
+		{"bandera que no cabe", "ab" + flagES, 3, "", "ab"},
+		{"VS16", "a" + smiley + "b", 3, Ellipsis, "a…"},
+		{"VS16 que cabe", "a" + smiley + "bc", 4, Ellipsis, "a" + smiley + "…"},
+
+		// Cola mas ancha que el ancho: se recorta sin cola
+		{"cola ancha", "hola", 2, "...", "ho"},
+		{"cola ancha CJK", "界界", 3, "界界", "界"},
+		{"ancho 0", "hola", 0, Ellipsis, ""},
+		{"ancho negativo", "hola", -3, Ellipsis, ""},
+
+		// Las secuencias de escape no cuentan y las posteriores al corte se conservan
+		{"estilo", "\x1b[1mhola mundo\x1b[0m", 6, Ellipsis, "\x1b[1mhola …\x1b[0m"},
+		{"enlace", "\x1b]8;;u\x1b\\enlace\x1b]8;;\x1b\\", 3, Ellipsis, "\x1b]8;;u\x1b\\en…\x1b]8;;\x1b\\"},
+	}
+
+	for _, test := range tests {
+		got := Truncate(test.text, test.width, test.tail)
+		if got != test.want { t.Errorf("%s: Truncate(%q, %d, %q) = %q, se esperaba %q", test.name, test.text, test.width, test.tail, got, test.want) }
+		if width := StringWidth(got); width > max(test.width, 0) { t.Errorf("%s: el resultado ocupa %d columnas", test.name, width) }
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name	string
+		text	string
+		width	int
+		want	[]string
+	}{
+		{"palabras", "hola mundo cruel", 10, []string{"hola mundo", "cruel"}},
+		{"palabra larga", "abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"saltos de linea", "a\n\nb", 10, []string{"a", "", "b"}},
+		{"espacios en el corte", "aaaa   bbbb", 4, []string{"aaaa", "bbbb"}},
+		{"CJK", "界界界", 5, []string{"界界", "界"}},
+		{"ZWJ", family + family + family, 4, []string{family + family, family}},
+		{"banderas", flagES + flagFR + flagES, 5, []string{flagES + flagFR, flagES}},
+
+		// El estilo activo en el corte se cierra y se vuelve a abrir en la linea siguiente
+		{"SGR", "\x1b[1;31mhola mundo\x1b[0m fin", 5, []string{
+			"\x1b[1;31mhola\x1b[0m",
+			"\x1b[1;31mmundo\x1b[0m",
+			"fin",
+		}},
+		{"SGR acumulado", "\x1b[1mab\x1b[31mcd ef", 2, []string{
+			"\x1b[1mab\x1b[31m\x1b[0m",
+			"\x1b[1m\x1b[31mcd\x1b[0m",
+			"\x1b[1m\x1b[31mef",
+		}},
+		{"SGR en varios cortes", "\x1b[4muno dos tres", 4, []string{
+			"\x1b[4muno\x1b[0m",
+			"\x1b[4mdos\x1b[0m",
+			"\x1b[4mtres",
+		}},
+		{"SGR con salto de linea", "\x1b[7ma\nb", 4, []string{"\x1b[7ma\x1b[0m", "\x1b[7mb"}},
+		{"SGR reiniciado", "\x1b[1ma\x1b[0m b", 1, []string{"\x1b[1ma\x1b[0m", "b"}},
+	}
+
+	for _, test := range tests {
+		got := Wrap(test.text, test.width)
+		if strings.Join(got, "|") != strings.Join(test.want, "|") {
+			t.Errorf("%s: Wrap(%q, %d) =\n\t%q\nse esperaba\n\t%q", test.name, test.text, test.width, got, test.want)
+		}
+		for _, line := range got {
+			if width := StringWidth(line); width > test.width { t.Errorf("%s: la linea %q ocupa %d columnas", test.name, line, width) }
+		}
+	}
+}
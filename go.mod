@@ -3,7 +3,6 @@ module github.com/Leontas-9/terminal-go
 go 1.24.3
 
 require (
-	github.com/eiannone/keyboard v0.0.0-20220611211555-0d226195f203
 	golang.org/x/image v0.30.0
 	golang.org/x/sys v0.35.0
 )
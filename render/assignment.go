@@ -2,6 +2,7 @@ package terminal
 
 import (
 	"image"
+	"image/color"
 
 	"golang.org/x/image/draw"
 )
@@ -13,12 +14,38 @@ type RenderImage struct {
 	// Bordes en los que se desea ajustar la imagen dentro del terminal
 	Margin 		image.Rectangle
 
+	// Recorte de la imagen original (en coordenadas de Image) que se renderiza
+	// Un rectangulo vacio renderiza la imagen completa
+	Crop		image.Rectangle
+
+	// Giros y espejos aplicados a la imagen antes de escalarla
+	Transform	Transform
+
 	// Punto inicial en donde se posicionara la imagen (el punto superior izquierdo)
 	InitialPoint	image.Point
 
 	// Tipo de interpolador
 	Interpolator draw.Interpolator
 
+	// Escala efectiva de la imagen (pixeles de terminal por pixel de origen)
+	// ZoomFit (0) ajusta la imagen a los bordes, 1 muestra los pixeles 1:1
+	Zoom		float64
+
+	// Desplazamiento de la porcion visible dentro de la imagen escalada
+	// Solo se usa cuando con el Zoom actual la imagen no cabe en los bordes
+	Viewport	image.Point
+
+	// Cantidad de goroutines para renderizar (y escalar) la imagen
+	// 0 -> automatico segun el tamaño de la imagen, 1 -> secuencial, n -> n goroutines
+	Workers		int
+
+	// Color sobre el que se componen los pixeles semitransparentes (los de alfa 0 no se pintan)
+	// Con alfa 0 se usa DefaultBackdrop; el fondo del terminal se elige con TerminalBackdrop
+	Backdrop	color.RGBA
+
+	// Dibuja la transparencia con los caracteres ░▒▓ en lugar de componerla sobre Backdrop
+	ShadeTransparency	bool
+
 	// Configuracion UI
 	// Permite cambiar la configuracion de la imagen
 	// como el cursor, pantalla alternativa, borrar pantalla, auto ajuste de imagen
@@ -26,21 +53,31 @@ type RenderImage struct {
 }
 
 type UI_Settings struct {
-	// Al final del renderizado muestra el cursor?
+	// Muestra el cursor durante el renderizado?
+	// Si es falso, el cursor se oculta mientras se imprime la imagen y luego
+	// vuelve a su estado anterior
 	ShowCursor 			bool
 	
 	// Cambia a la pantalla alternativa en el renderizado?
 	// Si es verdadero, la imagen final no se vera reflejada en la pantalla principal
 	// y se conservara todo dato impreso anteriormente a la imagen
+	// Si ya se esta en la pantalla alternativa (por ejemplo en un visor) no se cambia nada
 	AlternativeScreen	bool
 
-	// Borrar la imagen al finalizar?
+	// Borrar la pantalla antes de imprimir la imagen?
 	// Si es verdadero, no conservara los datos anteriores
 	EraseScreen			bool
 	
-	// Permite el auto ajuste de la imagen al final del renderizado?
-	// Si es verdadero, la imagen se ajustara a los bordes de la terminal
+	// Permite el auto ajuste de linea durante el renderizado?
+	// Si es falso, se desactiva mientras se imprime la imagen y luego vuelve a su estado anterior
 	Auto_Wrap			bool
+
+	// Imprime la imagen en la posicion actual del cursor, en lugar de en InitialPoint?
+	// Si es verdadero, la imagen empieza en la fila del cursor (InitialPoint.X es la columna
+	// y InitialPoint.Y no se usa), solo usa movimientos relativos y saltos de linea, y deja
+	// el cursor al inicio de la fila siguiente a la imagen. EraseScreen no se aplica
+	// Sirve para imprimir despues de otra salida, en el historial del terminal o redirigida
+	Inline				bool
 }
 
 // NewRenderImage crea una nueva imagen renderizable con los parametros especificados
@@ -74,6 +111,7 @@ func (UI_Settings) Default() *UI_Settings {
 			AlternativeScreen: false,
 			EraseScreen: false,
 			Auto_Wrap: true,
+			Inline: false,
 		}
 }
 
@@ -98,11 +136,29 @@ func (img *RenderImage) SetInterpolator(new draw.Interpolator) {
 	img.Interpolator = new
 }
 
+// SetWorkers cambia la cantidad de goroutines usadas para renderizar la imagen
+// 0 elige automaticamente, 1 fuerza el renderizado secuencial
+func (img *RenderImage) SetWorkers(new int) {
+	img.Workers = new
+}
+
+// SetBackdrop cambia el color sobre el que se componen los pixeles semitransparentes
+// (alfa 0 -> DefaultBackdrop, TerminalBackdrop() -> el fondo del terminal)
+func (img *RenderImage) SetBackdrop(new color.RGBA) {
+	img.Backdrop = new
+}
+
 // SetImage cambia la imagen que se renderizara
 func (img *RenderImage) SetImage(new *image.RGBA) {
 	img.Image = new
 }
 
+// SetCrop cambia la region de la imagen original que se renderizara
+// Un rectangulo vacio vuelve a renderizar la imagen completa
+func (img *RenderImage) SetCrop(new image.Rectangle) {
+	img.Crop = new
+}
+
 // SetInitialPoint cambia el punto inicial en donde se posicionara la imagen
 // Este punto es el punto superior izquierdo de la imagen dentro del terminal
 func (img *RenderImage) SetInitialPoint(new image.Point) {
@@ -114,7 +170,8 @@ func (img *RenderImage) SetInitialPoint(new image.Point) {
 // de la imagen, de lo contrario devuelve el tamaño del terminal
 // Esto se usa para ajustar la imagen al tamaño del terminal
 func DefaultSize(src image.Rectangle) image.Rectangle {
-	terminalRect := image.Rect(0, 0, terminalSize.X, terminalSize.Y)
+	size := cachedPixelSize()
+	terminalRect := image.Rect(0, 0, size.X, size.Y)
 	
 	if GetAreaRect(src) <= GetAreaRect(terminalRect){
 			return src
@@ -0,0 +1,111 @@
+package terminal
+
+import (
+	"image"
+	"image/color"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/image/draw"
+)
+
+// DefaultBackdrop es el fondo sobre el que se compone la transparencia de una imagen sin
+// Backdrop, y el que devuelve TerminalBackdrop si el terminal no informa del suyo
+var DefaultBackdrop = color.RGBA{A: 255}
+
+// Fondo del terminal, consultado una sola vez por TerminalBackdrop
+var terminalBackground struct {
+	mu		sync.Mutex
+	asked	bool
+	found	bool
+	color	color.RGBA
+}
+
+// activeLoops cuenta los EventLoop abiertos: mientras hay alguno no se consulta el terminal,
+// ya que el bucle se quedaria con la respuesta
+var activeLoops atomic.Int32
+
+// TerminalBackdrop devuelve el color de fondo del terminal (QueryBackground), consultado una sola vez
+// Devuelve DefaultBackdrop si la salida no es un terminal o este no responde; con un EventLoop
+// activo tambien, pero se vuelve a intentar en la siguiente llamada
+// No se usa por defecto: para componer sobre el fondo del terminal se pasa como Backdrop,
+// antes de empezar el EventLoop (src.SetBackdrop(TerminalBackdrop()))
+func TerminalBackdrop() color.RGBA {
+	terminalBackground.mu.Lock()
+	defer terminalBackground.mu.Unlock()
+
+	if !terminalBackground.asked && activeLoops.Load() == 0 {
+		terminalBackground.asked = true
+
+		// La consulta se escribe en la salida estandar: solo se hace si es un terminal
+		_, err := GetTerminalSize()
+		if err == nil {
+			terminalBackground.color, err = QueryBackground()
+			terminalBackground.found = err == nil
+		}
+	}
+
+	if terminalBackground.found { return terminalBackground.color }
+	return DefaultBackdrop
+}
+
+// backdrop devuelve el color (opaco) sobre el que se compone la imagen: Backdrop o DefaultBackdrop
+func (src *RenderImage) backdrop() color.RGBA {
+	backdrop := src.Backdrop
+	if backdrop.A == 0 { backdrop = DefaultBackdrop }
+
+	backdrop.A = 255
+	return backdrop
+}
+
+// compositeImage compone los pixeles semitransparentes de la imagen ajustada sobre el fondo (ver backdrop)
+// image.RGBA guarda los colores premultiplicados por el alfa: el resultado es color + fondo * (1 - alfa)
+// Los pixeles totalmente transparentes no se tocan, para que sus celdas se sigan saltando sin pintarlas
+// Una imagen opaca se devuelve sin cambios; si comparte pixeles con 'original' (a escala 1)
+// se compone sobre una copia del pool, para no modificar la imagen del llamador
+func (src *RenderImage) compositeImage(original *image.RGBA) *image.RGBA {
+	img := src.Image
+	if img.Opaque() { return img }
+
+	if sharesPixels(img, original) {
+		copied := GetReusableRGBA(image.Rect(0,0, img.Rect.Dx(), img.Rect.Dy()))
+		draw.Draw(copied, copied.Rect, img, img.Rect.Min, draw.Src)
+		img = copied
+	}
+
+	backdrop := src.backdrop()
+	for y := range img.Rect.Dy() {
+		row := img.Pix[y * img.Stride : y * img.Stride + img.Rect.Dx() * BPP]
+
+		for x := 0; x < len(row); x += BPP {
+			alpha := row[x+3]
+			if alpha == 0 || alpha == 255 { continue }
+
+			rest := 255 - uint32(alpha)
+			row[x]   = over(row[x], backdrop.R, rest)
+			row[x+1] = over(row[x+1], backdrop.G, rest)
+			row[x+2] = over(row[x+2], backdrop.B, rest)
+			row[x+3] = 255
+		}
+	}
+
+	return img
+}
+
+// over compone un canal premultiplicado sobre el del fondo; 'rest' es 255 - alfa
+func over(channel, backdrop uint8, rest uint32) uint8 {
+	return uint8(min(uint32(channel) + (uint32(backdrop) * rest + 127) / 255, 255))
+}
+
+// straightColor deshace el alfa premultiplicado de un pixel (para los caracteres ░▒▓)
+func straightColor(c color.RGBA) color.RGBA {
+	if c.A == 0 || c.A == 255 { return c }
+
+	a := uint32(c.A)
+	return color.RGBA{
+		R: uint8(min(uint32(c.R) * 255 / a, 255)),
+		G: uint8(min(uint32(c.G) * 255 / a, 255)),
+		B: uint8(min(uint32(c.B) * 255 / a, 255)),
+		A: c.A,
+	}
+}
@@ -0,0 +1,162 @@
+package terminal
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/Leontas-9/terminal-go/ansi"
+	"github.com/Leontas-9/terminal-go/vterm"
+)
+
+// edgeImage crea una imagen opaca con el borde a medio alfa (colores premultiplicados),
+// como los bordes suavizados de un icono
+func edgeImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0,0, width, height))
+	for y := range height {
+		for x := range width {
+			c := color.RGBA{R: uint8(40 + x * 20), G: uint8(60 + y * 15), B: 90, A: 255}
+			if x == 0 || y == 0 || x == width - 1 || y == height - 1 {
+				c = color.RGBA{R: c.R / 2, G: c.G / 2, B: c.B / 2, A: 128}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// composite calcula a mano un pixel premultiplicado sobre un fondo opaco: color + fondo * (1 - alfa)
+func composite(c, backdrop color.RGBA) color.RGBA {
+	rest := 255 - uint32(c.A)
+	channel := func(v, b uint8) uint8 { return uint8(uint32(v) + (uint32(b) * rest + 127) / 255) }
+	return color.RGBA{R: channel(c.R, backdrop.R), G: channel(c.G, backdrop.G), B: channel(c.B, backdrop.B), A: 255}
+}
+
+// screenImage aplica la salida de GetPNG a un terminal virtual y devuelve la imagen que dibuja
+func screenImage(t *testing.T, src *RenderImage) (*image.RGBA, *vterm.Screen, string) {
+	output, _, err := src.GetPNG()
+	if err != nil { t.Fatal(err) }
+
+	size := src.Image.Rect.Size()
+	screen := vterm.NewScreen(size.X + 2, size.Y / PPB + 2)
+	screen.Write(output)
+
+	return screen.Image(), screen, string(output)
+}
+
+// TestCompositeBackdrop compone los bordes a medio alfa sobre un fondo conocido y sobre
+// DefaultBackdrop (Backdrop sin color), sin consultar el terminal ni modificar la imagen
+func TestCompositeBackdrop(t *testing.T) {
+	img := edgeImage(7, 6)
+	original := bytes.Clone(img.Pix)
+
+	backdrops := []struct {
+		backdrop	color.RGBA
+		want		color.RGBA
+	}{
+		{color.RGBA{R: 10, G: 20, B: 200, A: 255}, color.RGBA{R: 10, G: 20, B: 200, A: 255}},
+		{color.RGBA{R: 10, G: 20, B: 200, A: 40}, color.RGBA{R: 10, G: 20, B: 200, A: 255}},
+		{color.RGBA{}, DefaultBackdrop},
+	}
+
+	for _, test := range backdrops {
+		src := NewImage(img)
+		src.SetBackdrop(test.backdrop)
+
+		got, _, output := screenImage(t, src)
+		if strings.ContainsAny(output, "░▒▓") { t.Errorf("fondo %v: la salida usa sombras en lugar de componer", test.backdrop) }
+
+		for y := range img.Rect.Dy() {
+			for x := range img.Rect.Dx() {
+				want := composite(img.RGBAAt(x, y), test.want)
+				if pixel := got.RGBAAt(x, y); pixel != want {
+					t.Fatalf("fondo %v: pixel (%d,%d) = %v, se esperaba %v", test.backdrop, x, y, pixel, want)
+				}
+			}
+		}
+	}
+
+	if !bytes.Equal(img.Pix, original) { t.Error("la composicion modifico la imagen del llamador") }
+
+	terminalBackground.mu.Lock()
+	defer terminalBackground.mu.Unlock()
+	if terminalBackground.asked { t.Error("se consulto el fondo del terminal sin pedirlo") }
+}
+
+// TestShadeTransparency comprueba que con ShadeTransparency no se compone: las celdas
+// semitransparentes se dibujan con ░▒▓ y las totalmente transparentes se saltan
+func TestShadeTransparency(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0,0, 6, 6))
+	for y := range 4 {
+		for x := range 6 {
+			// Filas 0 y 1: alfa 128 (▒); filas 2 y 3: alfa 64 (░); filas 4 y 5: transparentes
+			alpha := uint8(128)
+			if y >= 2 { alpha = 64 }
+			img.SetRGBA(x, y, color.RGBA{R: alpha, G: alpha / 2, A: alpha})
+		}
+	}
+
+	src := NewImage(img)
+	src.ShadeTransparency = true
+	src.SetBackdrop(color.RGBA{R: 255, A: 255})
+
+	_, screen, _ := screenImage(t, src)
+
+	rows := []rune{'▒', '░', ' '}
+	for row, want := range rows {
+		for x := range 6 {
+			cell := screen.Cell(x, row)
+			if cell.Rune != want { t.Errorf("celda (%d,%d) = %q, se esperaba %q", x, row, cell.Rune, want) }
+			if want == ' ' { continue }
+
+			// El color del texto es el del pixel sin premultiplicar
+			if fg := cell.Style.Foreground; fg.R != 255 || fg.G != 127 { t.Errorf("celda (%d,%d): color %v", x, row, fg) }
+		}
+	}
+}
+
+// TestCompositeTransparent comprueba que los pixeles totalmente transparentes no se componen:
+// sobre una pantalla llena de texto, las celdas con los dos pixeles transparentes se saltan
+// y conservan el texto; las semitransparentes se componen y se pintan encima
+func TestCompositeTransparent(t *testing.T) {
+	half := color.RGBA{R: 64, G: 32, A: 128}
+
+	// Fila de celdas 0: opaca; fila 1: transparente; fila 2: transparente a la izquierda y medio alfa a la derecha
+	img := image.NewRGBA(image.Rect(0,0, 6, 6))
+	for y := range 6 {
+		for x := range 6 {
+			switch {
+			case y < 2:			img.SetRGBA(x, y, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+			case y >= 4 && x >= 3:	img.SetRGBA(x, y, half)
+			}
+		}
+	}
+
+	src := NewImage(img)
+	src.SetBackdrop(color.RGBA{B: 255, A: 255})
+
+	output, _, err := src.GetPNG()
+	if err != nil { t.Fatal(err) }
+
+	screen := vterm.NewScreen(8, 5)
+	screen.WriteString(strings.Repeat("xxxxxxxx\r\n", 4) + "xxxxxxxx" + ansi.MoveTo(1, 1))
+	screen.Write(output)
+
+	for row := range 3 {
+		for x := range 6 {
+			cell := screen.Cell(x, row)
+			transparent := row == 1 || (row == 2 && x < 3)
+
+			if transparent && (cell.Rune != 'x' || cell.Style != (ansi.Style{})) {
+				t.Errorf("celda transparente (%d,%d) = %q %+v, se esperaba la 'x' de debajo", x, row, cell.Rune, cell.Style)
+			}
+			if !transparent && cell.Rune == 'x' { t.Errorf("celda (%d,%d) no se pinto", x, row) }
+		}
+	}
+
+	// La parte semitransparente se compone sobre el fondo
+	want := composite(half, color.RGBA{B: 255, A: 255})
+	if pixel := screen.Image().RGBAAt(4, 4); pixel != want { t.Errorf("pixel (4,4) = %v, se esperaba %v", pixel, want) }
+}
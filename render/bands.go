@@ -0,0 +1,73 @@
+package terminal
+
+import (
+	"bytes"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Parametros del renderizado por bandas
+const (
+	// minParallelCells es la cantidad minima de celdas para que el modo automatico
+	// reparta el trabajo entre varias goroutines (por debajo no compensa el costo)
+	minParallelCells = 64 * 1024
+
+	// minBandRows es la altura minima de una banda en filas de celdas
+	minBandRows = 4
+
+	// bandsPerWorker reparte mas bandas que goroutines para equilibrar la carga
+	bandsPerWorker = 4
+)
+
+// workerCount determina cuantas goroutines usar para renderizar 'rows' filas de celdas
+func (src *RenderImage) workerCount(rows int) int {
+	workers := src.Workers
+	if workers == 0 {
+		if rows * src.Image.Rect.Dx() < minParallelCells { return 1 }
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	return Clamp(workers, 1, max(rows / minBandRows, 1))
+}
+
+// renderBands divide la imagen en bandas horizontales de filas de celdas,
+// las codifica en paralelo en buffers propios y las une en orden dentro de buf.
+//
+// Cada banda empieza justo despues de un endLine, que deja los colores en su
+// valor por defecto, igual que en el camino secuencial; por eso la salida es
+// identica byte a byte a la de renderRows sobre toda la imagen.
+func (src *RenderImage) renderBands(buf *bytes.Buffer, rows, workers int, isYOdd bool) (err error) {
+	bandRows := max((rows + workers*bandsPerWorker - 1) / (workers*bandsPerWorker), minBandRows)
+	bands := make([]*bytes.Buffer, (rows + bandRows - 1) / bandRows)
+	errs := make([]error, len(bands))
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range min(workers, len(bands)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				band := int(next.Add(1) - 1)
+				if band >= len(bands) { return }
+
+				from := band * bandRows
+				to := min(from + bandRows, rows)
+
+				bands[band] = GetRenderBuffer()
+				errs[band] = src.renderRows(bands[band], from, to, isYOdd)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for band, blocks := range bands {
+		if err == nil { err = errs[band] }
+		if err == nil { _, err = buf.Write(blocks.Bytes()) }
+
+		PutRenderBuffer(blocks)
+	}
+
+	return err
+}
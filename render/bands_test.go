@@ -0,0 +1,86 @@
+package terminal
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// stripedImage crea una imagen con franjas horizontales de distinto alto, colores repetidos
+// entre filas vecinas (el atajo de sameColor) y, si 'alpha' es verdadero, pixeles semitransparentes
+func stripedImage(width, height int, alpha bool) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0,0, width, height))
+	for y := range height {
+		for x := range width {
+			c := color.RGBA{R: uint8(y / 3 * 40), G: uint8(x / 5 * 30), B: uint8((y / 7 + x / 11) * 20), A: 255}
+			if alpha && (x + y) % 9 == 0 { c = color.RGBA{R: c.R / 2, G: c.G / 2, B: c.B / 2, A: 128} }
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// renderWith renderiza la imagen ya ajustada (sin pasar por los bordes del terminal) con 'workers' goroutines
+func renderWith(t testing.TB, src RenderImage, workers int) []byte {
+	src.Workers = workers
+
+	var buf bytes.Buffer
+	err := src.renderTo(&buf)
+	if err != nil { t.Fatal(err) }
+
+	return buf.Bytes()
+}
+
+// TestRenderBandsSequential comprueba que el renderizado por bandas es identico byte a byte
+// al secuencial, con la fila inicial par e impar (las bandas empiezan en filas de distinta paridad)
+func TestRenderBandsSequential(t *testing.T) {
+	images := map[string]*image.RGBA{
+		"even":		stripedImage(97, 120, false),
+		"odd":		stripedImage(64, 133, false),
+		"alpha":	stripedImage(81, 101, true),
+	}
+
+	for name, img := range images {
+		for _, y := range []int{0, 1, 4, 7} {
+			for _, shade := range []bool{false, true} {
+				src := *NewImage(img)
+				src.InitialPoint = image.Pt(2, y)
+				src.ShadeTransparency = shade
+
+				want := renderWith(t, src, 1)
+				for _, workers := range []int{2, 3, 4, 8, 16} {
+					got := renderWith(t, src, workers)
+					if !bytes.Equal(got, want) {
+						t.Errorf("%s, Y=%d, shade=%v, workers=%d: la salida difiere de la secuencial (%d bytes, se esperaban %d)",
+							name, y, shade, workers, len(got), len(want))
+					}
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkRenderBands renderiza un cuadro de 4K (3840x2160) con 1, 2, 4 y 8 goroutines
+func BenchmarkRenderBands(b *testing.B) {
+	frame := testImage(3840, 2160, 1)
+
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			src := *NewImage(frame)
+			src.Workers = workers
+
+			buf := GetRenderBuffer()
+			defer PutRenderBuffer(buf)
+
+			b.SetBytes(int64(len(frame.Pix)))
+			b.ReportAllocs()
+			for b.Loop() {
+				buf.Reset()
+				err := src.renderTo(buf)
+				if err != nil { b.Fatal(err) }
+			}
+		})
+	}
+}
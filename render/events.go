@@ -0,0 +1,211 @@
+package terminal
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Leontas-9/terminal-go/ansi"
+)
+
+// EventKind identifica el origen de un Event
+type EventKind int
+
+// Origenes de los eventos del bucle
+const (
+	EventInput	EventKind = iota	// tecla o raton (Event.Input)
+	EventResize						// cambio de tamaño del terminal (Event.Size)
+	EventTick						// tick de animacion (Event.Time)
+)
+
+// Event es un evento del bucle: una entrada, un cambio de tamaño o un tick
+type Event struct {
+	Kind	EventKind
+
+	// Tecla o evento del raton (EventInput)
+	Input	ansi.InputEvent
+
+	// Nuevo tamaño del terminal en columnas y filas (EventResize)
+	Size	image.Point
+
+	// Momento del tick (EventTick)
+	Time	time.Time
+}
+
+// EventOptions configura el terminal y los eventos de un EventLoop
+type EventOptions struct {
+	// Activa el seguimiento del raton
+	Mouse				bool
+
+	// Cambia a la pantalla alternativa mientras dura el bucle
+	AlternativeScreen	bool
+
+	// Intervalo de los ticks de animacion (0 -> sin ticks, ver SetTick)
+	Tick				time.Duration
+
+	// Tiempo que se agrupan los cambios de tamaño (0 -> ResizeDebounce)
+	ResizeDebounce		time.Duration
+}
+
+// EventLoop reune en un solo canal la entrada (teclas y raton), los cambios de tamaño,
+// los ticks de animacion y la cancelacion del contexto
+// Mientras dura deja el terminal en modo crudo; Close (o una señal de terminacion)
+// restaura exactamente lo que cambio (ver TerminalState): el modo, el raton y la pantalla principal
+type EventLoop struct {
+	events	chan Event
+	ticks	chan time.Duration
+	cancel	context.CancelFunc
+	state	*TerminalState
+	input	*inputSession
+	watcher	*ResizeWatcher
+	options	EventOptions
+
+	mu		sync.Mutex
+	err		error
+	closed	bool
+	once	sync.Once
+}
+
+// StartEventLoop pasa el terminal a modo crudo y empieza a enviar eventos hasta que se
+// cancela ctx, llega una señal de terminacion (SIGINT, SIGTERM, SIGHUP), falla la lectura
+// de la entrada o se llama a Close
+// Se debe llamar a Close (normalmente con defer, que tambien se ejecuta en un panic)
+func StartEventLoop(ctx context.Context, options EventOptions) (*EventLoop, error) {
+	state := newTerminalState(false)
+	input, err := openInput(state, options.Mouse)
+	if err != nil {
+		state.Close()
+		return nil, err
+	}
+
+	if options.ResizeDebounce <= 0 { options.ResizeDebounce = ResizeDebounce }
+	if options.AlternativeScreen { state.AlternativeScreen(true) }
+
+	activeLoops.Add(1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	loop := &EventLoop{
+		events:		make(chan Event),
+		ticks:		make(chan time.Duration, 1),
+		cancel:		cancel,
+		state:		state,
+		input:		input,
+		watcher:	WatchResize(options.ResizeDebounce),
+		options:	options,
+	}
+
+	go loop.run(ctx)
+	return loop, nil
+}
+
+// Events devuelve el canal de eventos; se cierra cuando termina el bucle (ver Err)
+func (loop *EventLoop) Events() <-chan Event {
+	return loop.events
+}
+
+// Err devuelve por que termino el bucle: el error del contexto, de la entrada o de la señal
+// Devuelve nil mientras el bucle sigue activo o si termino con Close
+func (loop *EventLoop) Err() error {
+	loop.mu.Lock()
+	defer loop.mu.Unlock()
+
+	return loop.err
+}
+
+// SetTick cambia el intervalo de los ticks de animacion (0 los detiene)
+func (loop *EventLoop) SetTick(interval time.Duration) {
+	// Solo importa el ultimo intervalo pedido
+	select {
+	case <-loop.ticks:
+	default:
+	}
+	loop.ticks <- interval
+}
+
+// Close termina el bucle y restaura el terminal; se puede llamar varias veces
+func (loop *EventLoop) Close() error {
+	var err error
+	loop.once.Do(func() {
+		loop.mu.Lock()
+		loop.closed = true
+		loop.mu.Unlock()
+
+		loop.cancel()
+		loop.watcher.Stop()
+
+		os.Stdout.Write(resetColor)
+		err = loop.state.Close()
+		activeLoops.Add(-1)
+	})
+	return err
+}
+
+// stop guarda el motivo por el que termina el bucle (solo el primero)
+func (loop *EventLoop) stop(err error) {
+	loop.mu.Lock()
+	defer loop.mu.Unlock()
+
+	if loop.err == nil && !loop.closed { loop.err = err }
+}
+
+// run reune las fuentes de eventos en loop.events hasta que termina el bucle
+func (loop *EventLoop) run(ctx context.Context) {
+	defer close(loop.events)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	var ticker *time.Ticker
+	var tick <-chan time.Time
+	setTick := func(interval time.Duration) {
+		if ticker != nil { ticker.Stop() }
+		ticker, tick = nil, nil
+		if interval > 0 {
+			ticker = time.NewTicker(interval)
+			tick = ticker.C
+		}
+	}
+	setTick(loop.options.Tick)
+	defer setTick(0)
+
+	for {
+		var event Event
+
+		select {
+		case <-ctx.Done():
+			loop.stop(ctx.Err())
+			return
+		case sig := <-signals:
+			// Restaura el terminal aunque quien lee los eventos no llegue a llamar a Close
+			loop.stop(fmt.Errorf("terminal: interrumpido por la señal %v", sig))
+			loop.Close()
+			return
+		case result := <-loop.input.events:
+			if result.err != nil {
+				loop.stop(result.err)
+				return
+			}
+			event = Event{Kind: EventInput, Input: result.event}
+		case size := <-loop.watcher.C:
+			event = Event{Kind: EventResize, Size: size}
+		case now := <-tick:
+			event = Event{Kind: EventTick, Time: now}
+		case interval := <-loop.ticks:
+			setTick(interval)
+			continue
+		}
+
+		select {
+		case loop.events <- event:
+		case <-ctx.Done():
+			loop.stop(ctx.Err())
+			return
+		}
+	}
+}
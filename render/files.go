@@ -11,6 +11,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"golang.org/x/image/bmp"
 	"golang.org/x/image/tiff"
 	"golang.org/x/image/webp"
@@ -19,6 +21,9 @@ import (
 //LoadImage carga una imagen desde un archivo y la convierte a formato RGBA.
 // Si el archivo no se puede abrir o el formato no es compatible, devuelve un error.
 // Formatos soportados: JPEG, PNG, BMP, TIFF, WebP y GIF (solo la primera imagen del GIF).
+// La imagen siempre viene del pool, con origen (0,0), y pertenece al llamador: se devuelve
+// con PutReusableRGBA al terminar de usarla (solo las mayores que la clase mas grande, 64 MiB,
+// se asignan aparte y el pool las descarta)
 func LoadImage(filepath string) (*image.RGBA, error) {
 	file, err := OpenFile(filepath)
 	if err != nil { return nil, err }
@@ -27,12 +32,28 @@ func LoadImage(filepath string) (*image.RGBA, error) {
 	img, err := DecodeImage(file, filepath)
 	if err != nil { return nil, fmt.Errorf("decode: %v", err) }
 
+	// ConvertToRGBA devuelve tal cual una imagen RGBA del decodificador: se copia al pool
+	if rgba, ok := img.(*image.RGBA); ok { return copyRGBA(rgba), nil }
+
 	dst, err := ConvertToRGBA(img)
 	if err != nil { return nil, fmt.Errorf("convertToRGBA: %v", err) }
 
 	return dst, nil
 }
 
+// copyRGBA copia una imagen RGBA en una imagen del pool con origen (0,0)
+func copyRGBA(src *image.RGBA) *image.RGBA {
+	dst := GetReusableRGBA(src.Rect)
+	rowSize := dst.Rect.Dx() * BPP
+
+	for y := range dst.Rect.Dy() {
+		start := src.PixOffset(src.Rect.Min.X, src.Rect.Min.Y + y)
+		copy(dst.Pix[y * dst.Stride : y * dst.Stride + rowSize], src.Pix[start : start + rowSize])
+	}
+
+	return dst
+}
+
 // DecodeImage decodifica una imagen desde un lector de archivos
 // según su extensión. Soporta formatos comunes como JPEG, PNG, BMP, TIFF, WebP y GIF.
 // (En el case de GIF este codifica unuicamente la primera imagen del GIF). 
@@ -56,6 +77,14 @@ func DecodeImage(file io.Reader, fileName string) (img  image.Image, err error)
 	return
 }
 
+// Extensiones de imagen que DecodeImage reconoce por su nombre
+var SupportedExtensions = []string{".jpeg", ".jpg", ".png", ".bmp", ".tiff", ".webp", ".gif"}
+
+// IsSupportedImage indica si el archivo tiene una extension de imagen soportada
+func IsSupportedImage(fileName string) bool {
+	return slices.Contains(SupportedExtensions, strings.ToLower(filepath.Ext(fileName)))
+}
+
 // OpenFile abre un archivo en la ruta especificada y devuelve un puntero al archivo.
 // Si hay un error al cambiar el directorio o al abrir el archivo, devuelve un error
 func OpenFile(filepath string) (*os.File, error) {
@@ -68,13 +97,14 @@ func OpenFile(filepath string) (*os.File, error) {
 // ConvertToRGBA convierte una imagen a formato RGBA.
 // Si la imagen ya es de tipo RGBA, la devuelve directamente.
 // esto evita la necesidad de crear una nueva imagen innecesariamente.
+// La copia viene del pool (GetReusableRGBA) y empieza en (0,0) aunque la imagen tenga otro origen.
 func ConvertToRGBA(src image.Image) (*image.RGBA, error) {
 	dst ,ok := src.(*image.RGBA)
 	if ok { return dst, nil	}
 
 	dst = GetReusableRGBA(src.Bounds())
 	
-	draw.Draw(dst, src.Bounds(), src, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Rect, src, src.Bounds().Min, draw.Src)
 
 	return dst, nil
 }
\ No newline at end of file
@@ -0,0 +1,303 @@
+package terminal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/Leontas-9/terminal-go/ansi"
+
+	"golang.org/x/image/draw"
+)
+
+// Gallery recorre una lista de imagenes de forma interactiva
+// Carga en segundo plano las imagenes vecinas a la actual y devuelve al pool
+// (PutReusableRGBA) las que quedan lejos a medida que se avanza
+type Gallery struct {
+	// Rutas de las imagenes, en el orden en que se muestran
+	Paths			[]string
+
+	// Indice de la imagen actual dentro de Paths
+	Index			int
+
+	// Interpolador con el que se escala cada imagen
+	Interpolator	draw.Interpolator
+
+	// Cantidad de goroutines para renderizar cada imagen (ver RenderImage.Workers)
+	Workers			int
+
+	// Muestra una linea de estado con la posicion y el nombre del archivo
+	StatusLine		bool
+
+	// Teclas y desplazamiento del modo interactivo (nil -> LoadViewer)
+	Viewer			*Viewer
+
+	mu		sync.Mutex
+	cache	map[int]*galleryEntry
+}
+
+// galleryEntry es una imagen cargada (o cargandose) en segundo plano
+// img y err solo se leen despues de cerrarse ready
+type galleryEntry struct {
+	img		*image.RGBA
+	err		error
+	ready	chan struct{}
+}
+
+// DefaultStatusStyle es el estilo por defecto de la linea de estado y de la ayuda (ver Viewer.StatusStyle)
+var DefaultStatusStyle = ansi.Style{
+	Foreground: color.RGBA{R: 20, G: 20, B: 20, A: 255},
+	Background: color.RGBA{R: 200, G: 200, B: 200, A: 255},
+}
+
+// NewGallery crea una galeria con las rutas indicadas
+func NewGallery(paths []string) *Gallery {
+	return &Gallery{
+		Paths:			paths,
+		Interpolator:	draw.NearestNeighbor,
+		StatusLine:		true,
+		cache:			make(map[int]*galleryEntry),
+	}
+}
+
+// NewGalleryGlob crea una galeria con las imagenes que coinciden con un patron
+// (por ejemplo "fotos/*.jpg"). Si el patron es un directorio se usan todas sus
+// imagenes con una extension soportada
+func NewGalleryGlob(pattern string) (*Gallery, error) {
+	info, err := os.Stat(pattern)
+	if err == nil && info.IsDir() { pattern = filepath.Join(pattern, "*") }
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil { return nil, err }
+
+	paths := slices.DeleteFunc(matches, func(path string) bool { return !IsSupportedImage(path) })
+	if len(paths) == 0 { return nil, fmt.Errorf("no hay imagenes para %q", pattern) }
+
+	return NewGallery(paths), nil
+}
+
+// Show muestra la galeria en la pantalla alternativa hasta la accion Quit (Esc, Ctrl+C o q)
+// Next/Prev (n/PgDn, p/PgUp) cambian de imagen y First/Last (Home/End) van a la primera y ultima;
+// CopyPath (c) copia la ruta de la imagen actual al portapapeles y el resto de acciones
+// y los eventos del raton se aplican a la imagen como en Displacement
+func (g *Gallery) Show() error {
+	return g.ShowContext(context.Background())
+}
+
+// ShowContext es como Show, pero tambien termina (con el error del contexto) al cancelarse ctx
+func (g *Gallery) ShowContext(ctx context.Context) error {
+	if len(g.Paths) == 0 { return errors.New("gallery: no images") }
+
+	viewer := g.Viewer
+	if viewer == nil {
+		var err error
+		viewer, err = LoadViewer()
+		if err != nil { return err }
+	}
+
+	loop, err := StartEventLoop(ctx, EventOptions{Mouse: viewer.Mouse, AlternativeScreen: true})
+	if err != nil { return err }
+	defer loop.Close()
+	defer g.Close()
+	defer viewer.pushTitle()()
+
+	g.Index = Clamp(g.Index, 0, len(g.Paths) - 1)
+	src := g.current()
+	err = g.draw(src, viewer)
+	if err != nil { return err }
+
+	for event := range loop.Events() {
+		if event.Kind == EventResize {
+			if src != nil { src.Margin = g.margin() }
+			err = g.draw(src, viewer)
+			if err != nil { return err }
+			continue
+		}
+		if event.Kind != EventInput { continue }
+
+		lastIndex := g.Index
+		switch viewer.Action(event.Input) {
+		case Quit:
+			os.Stdout.Write(moveToStart)
+			os.Stdout.Write(eraseScreen_FromCursor)
+			return nil
+		case Next:
+			g.Next()
+		case Prev:
+			g.Prev()
+		case First:
+			g.First()
+		case Last:
+			g.Last()
+		case CopyPath:
+			viewer.copyPath(g.Paths[g.Index])
+		default:
+			if src == nil { continue }
+
+			lastPosition := src.InitialPoint
+			if viewer.apply(src, event.Input) || !lastPosition.Eq(src.InitialPoint) {
+				err = g.draw(src, viewer)
+				if err != nil { return err }
+			}
+			continue
+		}
+
+		if g.Index != lastIndex {
+			src = g.current()
+			err = g.draw(src, viewer)
+			if err != nil { return err }
+		}
+	}
+
+	return loop.Err()
+}
+
+// Next avanza a la siguiente imagen
+func (g *Gallery) Next() {
+	g.Index = min(g.Index + 1, len(g.Paths) - 1)
+}
+
+// Prev retrocede a la imagen anterior
+func (g *Gallery) Prev() {
+	g.Index = max(g.Index - 1, 0)
+}
+
+// First va a la primera imagen
+func (g *Gallery) First() {
+	g.Index = 0
+}
+
+// Last va a la ultima imagen
+func (g *Gallery) Last() {
+	g.Index = len(g.Paths) - 1
+}
+
+// Close devuelve al pool todas las imagenes cargadas por la galeria
+func (g *Gallery) Close() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for index, entry := range g.cache {
+		releaseEntry(entry)
+		delete(g.cache, index)
+	}
+}
+
+// current espera a que la imagen actual este cargada, lanza la carga de sus
+// vecinas y devuelve la imagen lista para renderizar (nil si no se pudo cargar)
+func (g *Gallery) current() *RenderImage {
+	entry := g.load(g.Index)
+	g.preload()
+
+	<-entry.ready
+	if entry.err != nil { return nil }
+
+	interpolator := g.Interpolator
+	if interpolator == nil { interpolator = draw.NearestNeighbor }
+
+	src := newRenderImage(entry.img, g.margin(), image.Point{}, interpolator, *UI_Settings{}.Default())
+	src.Workers = g.Workers
+
+	return src
+}
+
+// load devuelve la entrada de una imagen, iniciando su carga si no estaba en cache
+func (g *Gallery) load(index int) *galleryEntry {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.cache == nil { g.cache = make(map[int]*galleryEntry) }
+
+	entry, ok := g.cache[index]
+	if ok { return entry }
+
+	entry = &galleryEntry{ready: make(chan struct{})}
+	g.cache[index] = entry
+
+	go func(path string) {
+		entry.img, entry.err = LoadImage(path)
+		close(entry.ready)
+	}(g.Paths[index])
+
+	return entry
+}
+
+// preload carga en segundo plano las imagenes vecinas de la actual
+// y libera las que quedan fuera de ese rango
+func (g *Gallery) preload() {
+	for _, index := range []int{g.Index + 1, g.Index - 1} {
+		if index >= 0 && index < len(g.Paths) { g.load(index) }
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for index, entry := range g.cache {
+		if index < g.Index - 1 || index > g.Index + 1 {
+			releaseEntry(entry)
+			delete(g.cache, index)
+		}
+	}
+}
+
+// releaseEntry devuelve la imagen al pool cuando termina de cargarse
+func releaseEntry(entry *galleryEntry) {
+	go func() {
+		<-entry.ready
+		PutReusableRGBA(entry.img)
+	}()
+}
+
+// margin devuelve los bordes disponibles para la imagen, reservando
+// la ultima fila del terminal para la linea de estado
+func (g *Gallery) margin() image.Rectangle {
+	size, err := GetTerminalPixelSize()
+	if err != nil { size = cachedPixelSize() }
+
+	if g.StatusLine { size.Y -= PPB }
+
+	return image.Rect(0,0, size.X, max(size.Y, PPB))
+}
+
+// draw limpia la pantalla, dibuja la imagen actual, la linea de estado y la ayuda del visor
+// Devuelve el error al escribir la imagen (una imagen que no se pudo cargar no es un error)
+func (g *Gallery) draw(src *RenderImage, viewer *Viewer) error {
+	os.Stdout.Write(moveToStart)
+	os.Stdout.Write(eraseScreen_FromCursor)
+
+	status := fmt.Sprintf(" %d/%d ", g.Index + 1, len(g.Paths))
+	if src == nil {
+		status += fmt.Sprintf(" %s (no se pudo cargar la imagen) ", filepath.Base(g.Paths[g.Index]))
+	} else {
+		_, err := src.Print()
+		if err != nil { return err }
+		status += viewer.statusText(src, g.Paths[g.Index])
+	}
+	viewer.setTitle(g.Paths[g.Index])
+
+	if g.StatusLine { writeStatusLine(status, viewer.StatusStyle) }
+	if viewer.help { viewer.writeHelp() }
+
+	return nil
+}
+
+// writeStatusLine escribe un texto con el estilo dado en la ultima fila del terminal,
+// recortado al ancho para que no salte de linea (y desplace la pantalla)
+func writeStatusLine(text string, style ansi.Style) {
+	size, err := GetTerminalSize()
+	if err != nil { return }
+
+	var sb strings.Builder
+	sb.WriteString(ansi.MoveTo(1, size.Y))
+	sb.WriteString(ansi.EraseLine())
+	sb.WriteString(style.PaintString(ansi.Truncate(text, size.X, ansi.Ellipsis), true))
+
+	os.Stdout.WriteString(sb.String())
+}
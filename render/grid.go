@@ -0,0 +1,453 @@
+package terminal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Leontas-9/terminal-go/ansi"
+
+	"golang.org/x/image/draw"
+)
+
+// Valores por defecto de la cuadricula de miniaturas
+const (
+	// DefaultThumbWidth es el ancho (en columnas) de cada miniatura cuando Columns es 0
+	DefaultThumbWidth = 24
+)
+
+// Grid dibuja varias imagenes como miniaturas en una cuadricula dentro del terminal
+// Muestra tantas miniaturas como caben y reparte el resto en paginas
+type Grid struct {
+	// Rutas de las imagenes, en el orden en que se muestran
+	Paths			[]string
+
+	// Cantidad de columnas (0 -> las que quepan con DefaultThumbWidth)
+	Columns			int
+
+	// Separacion entre miniaturas: X en columnas, Y en filas del terminal
+	Spacing			image.Point
+
+	// Dibuja el nombre del archivo debajo de cada miniatura
+	Captions		bool
+
+	// Area del terminal (en columnas y filas) donde se dibuja la cuadricula
+	// Un rectangulo vacio usa el terminal completo
+	Area			image.Rectangle
+
+	// Interpolador con el que se escala cada miniatura
+	Interpolator	draw.Interpolator
+
+	// Cantidad de imagenes que se cargan a la vez para una pagina (0 -> GOMAXPROCS)
+	// Cada carga decodifica la imagen completa, por lo que limita la memoria usada
+	Workers			int
+
+	// Pagina actual
+	Page			int
+
+	// Indice (en Paths) de la miniatura seleccionada, -1 si no hay ninguna
+	// Su texto se dibuja resaltado y Enter la abre en Show
+	Selected		int
+
+	// Estilos del texto de las miniaturas y del texto de la miniatura seleccionada
+	CaptionStyle	ansi.Style
+	SelectedStyle	ansi.Style
+
+	// Teclas y desplazamiento del modo interactivo (nil -> LoadViewer)
+	Viewer			*Viewer
+
+	// Mensaje de la linea de estado (por ejemplo si no se pudo abrir una imagen)
+	// Se borra con la siguiente tecla
+	message		string
+}
+
+// GridLayout es la distribucion de las miniaturas calculada para el terminal actual
+// Todas las medidas estan en celdas del terminal (columnas y filas)
+type GridLayout struct {
+	// Area ocupada por la cuadricula
+	Area		image.Rectangle
+
+	// Columnas y filas de miniaturas por pagina
+	Columns		int
+	Rows		int
+
+	// Tamaño de la miniatura (sin el texto)
+	Thumb		image.Point
+
+	// Separacion entre miniaturas
+	Spacing		image.Point
+
+	// Filas reservadas para el texto de cada miniatura (0 o 1)
+	Caption		int
+}
+
+// Estilos por defecto del texto de las miniaturas (el seleccionado se dibuja invertido y en negrita)
+var (
+	DefaultCaptionStyle		= ansi.Style{Foreground: color.RGBA{R: 200, G: 200, B: 200, A: 255}}
+	DefaultSelectedStyle	= ansi.Style{Foreground: color.RGBA{R: 200, G: 200, B: 200, A: 255}, Attrs: ansi.Reverse | ansi.Bold}
+)
+
+// NewGrid crea una cuadricula con las rutas indicadas
+func NewGrid(paths []string) *Grid {
+	return &Grid{
+		Paths:			paths,
+		Spacing:		image.Pt(2, 1),
+		Captions:		true,
+		Interpolator:	draw.BiLinear,
+		Selected:		-1,
+		CaptionStyle:	DefaultCaptionStyle,
+		SelectedStyle:	DefaultSelectedStyle,
+	}
+}
+
+// Layout calcula la distribucion de las miniaturas para el terminal actual
+func (g *Grid) Layout() GridLayout {
+	area := g.Area
+	if area.Empty() {
+		size, err := GetTerminalSize()
+		if err != nil { size = cachedPixelSize(); size.Y /= PPB }
+		area = image.Rect(0,0, size.X, size.Y)
+	}
+
+	layout := GridLayout{Area: area, Spacing: g.Spacing}
+	if g.Captions { layout.Caption = 1 }
+
+	layout.Columns = g.Columns
+	if layout.Columns <= 0 {
+		layout.Columns = (area.Dx() + g.Spacing.X) / (DefaultThumbWidth + g.Spacing.X)
+	}
+	layout.Columns = max(layout.Columns, 1)
+
+	// Miniaturas cuadradas en pixeles: cada fila del terminal tiene dos pixeles de alto
+	width := max((area.Dx() - (layout.Columns - 1) * g.Spacing.X) / layout.Columns, 1)
+	height := max(min((width + 1) / PPB, area.Dy() - layout.Caption), 1)
+	layout.Thumb = image.Pt(width, height)
+
+	layout.Rows = max((area.Dy() + g.Spacing.Y) / (height + layout.Caption + g.Spacing.Y), 1)
+
+	return layout
+}
+
+// PerPage devuelve cuantas miniaturas caben en una pagina
+func (layout GridLayout) PerPage() int {
+	return layout.Columns * layout.Rows
+}
+
+// Cell devuelve el rectangulo (en celdas) de la miniatura 'slot' de la pagina, incluido su texto
+func (layout GridLayout) Cell(slot int) image.Rectangle {
+	column, row := slot % layout.Columns, slot / layout.Columns
+	origin := layout.Area.Min.Add(image.Pt(
+		column * (layout.Thumb.X + layout.Spacing.X),
+		row * (layout.Thumb.Y + layout.Caption + layout.Spacing.Y),
+	))
+
+	return image.Rectangle{Min: origin, Max: origin.Add(layout.Thumb).Add(image.Pt(0, layout.Caption))}
+}
+
+// SlotAt devuelve la miniatura de la pagina que contiene la celda 'point', o -1
+func (layout GridLayout) SlotAt(point image.Point) int {
+	for slot := range layout.PerPage() {
+		if point.In(layout.Cell(slot)) { return slot }
+	}
+
+	return -1
+}
+
+// PageCount devuelve la cantidad de paginas
+func (g *Grid) PageCount() int {
+	perPage := g.Layout().PerPage()
+	return max((len(g.Paths) + perPage - 1) / perPage, 1)
+}
+
+// RenderPage renderiza una pagina completa de miniaturas en formato ANSI
+// Las imagenes se cargan en paralelo (ver Workers) y vuelven al pool al terminar
+func (g *Grid) RenderPage(page int) ([]byte, error) {
+	blocks := GetRenderBuffer()
+	defer PutRenderBuffer(blocks)
+
+	err := g.AppendPage(blocks, page)
+	if err != nil { return nil, err }
+
+	return bytes.Clone(blocks.Bytes()), nil
+}
+
+// AppendPage renderiza una pagina de miniaturas al final de un buffer del llamador
+func (g *Grid) AppendPage(blocks *bytes.Buffer, page int) error {
+	layout := g.Layout()
+	first := page * layout.PerPage()
+	if first < 0 || first >= len(g.Paths) { return errors.New("grid: page out of range") }
+	paths := g.Paths[first:min(first + layout.PerPage(), len(g.Paths))]
+
+	thumbs, errs := g.renderThumbs(paths, layout)
+	defer func() {
+		for _, thumb := range thumbs { PutRenderBuffer(thumb) }
+	}()
+
+	for slot, thumb := range thumbs {
+		cell := layout.Cell(slot)
+
+		if errs[slot] != nil { return errs[slot] }
+		if thumb != nil { blocks.Write(thumb.Bytes()) }
+
+		if layout.Caption > 0 {
+			style := g.CaptionStyle
+			if first + slot == g.Selected { style = g.SelectedStyle }
+
+			blocks.WriteString(ansi.MoveTo(cell.Min.X + 1, cell.Max.Y))
+			blocks.WriteString(style.PaintString(ansi.Truncate(filepath.Base(paths[slot]), layout.Thumb.X, ansi.Ellipsis), true))
+		}
+	}
+
+	return nil
+}
+
+// renderThumbs carga y renderiza las miniaturas de una pagina con a lo sumo Workers goroutines
+// a la vez; cada imagen vuelve al pool en cuanto se renderiza su miniatura, por lo que nunca hay
+// mas de Workers imagenes completas en memoria
+// Las que no se pueden cargar quedan en nil (la miniatura solo muestra su nombre)
+func (g *Grid) renderThumbs(paths []string, layout GridLayout) (thumbs []*bytes.Buffer, errs []error) {
+	workers := g.Workers
+	if workers <= 0 { workers = runtime.GOMAXPROCS(0) }
+
+	thumbs = make([]*bytes.Buffer, len(paths))
+	errs = make([]error, len(paths))
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range min(workers, len(paths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(paths) { return }
+
+				img, err := LoadImage(paths[i])
+				if err != nil { continue }
+
+				thumbs[i] = GetRenderBuffer()
+				errs[i] = g.appendThumb(thumbs[i], img, layout.Cell(i), layout.Thumb)
+				PutReusableRGBA(img)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return thumbs, errs
+}
+
+// appendThumb escala una imagen al tamaño de la miniatura y la centra en su celda
+func (g *Grid) appendThumb(blocks *bytes.Buffer, img *image.RGBA, cell image.Rectangle, thumb image.Point) error {
+	interpolator := g.Interpolator
+	if interpolator == nil { interpolator = draw.BiLinear }
+
+	src := newRenderImage(img, image.Rect(0,0, thumb.X, thumb.Y * PPB), image.Point{}, interpolator, *UI_Settings{}.Default())
+	size := src.scaledSize(src.CalculateScale())
+
+	// La fila de pixeles inicial se mantiene par para que cada celda tenga dos pixeles completos
+	src.InitialPoint = image.Pt(
+		cell.Min.X + (thumb.X - size.X) / 2,
+		(cell.Min.Y + (thumb.Y - (size.Y + 1) / PPB) / 2) * PPB,
+	)
+
+	dst, err := src.prepareRender()
+	if err != nil { return err }
+	if !sharesPixels(dst.Image, img) { defer PutReusableRGBA(dst.Image) }
+
+	// Sin configuraciones de UI: la cuadricula completa se dibuja como una sola salida
+	startCol, startRow := dst.calculateStartPosition()
+	blocks.WriteString(ansi.MoveTo(startCol, startRow))
+
+	err = dst.renderBlocks(blocks)
+	if err != nil { return err }
+
+	_, err = blocks.Write(resetColor)
+	return err
+}
+
+// Print dibuja la pagina actual en el terminal
+func (g *Grid) Print() error {
+	blocks := GetRenderBuffer()
+	defer PutRenderBuffer(blocks)
+
+	err := g.AppendPage(blocks, g.Page)
+	if err != nil { return err }
+
+	_, err = blocks.WriteTo(os.Stdout)
+	return err
+}
+
+// Show muestra la cuadricula en la pantalla alternativa hasta la accion Quit (Esc, Ctrl+C o q)
+// Next/Prev (n/PgDn, p/PgUp) cambian de pagina y First/Last (Home/End) van a la primera y ultima;
+// un clic (o los desplazamientos) selecciona una miniatura, Open (Enter) la abre como en Displacement
+// y CopyPath (c) copia su ruta al portapapeles
+func (g *Grid) Show() error {
+	return g.ShowContext(context.Background())
+}
+
+// ShowContext es como Show, pero tambien termina (con el error del contexto) al cancelarse ctx
+func (g *Grid) ShowContext(ctx context.Context) error {
+	if len(g.Paths) == 0 { return errors.New("grid: no images") }
+
+	viewer := g.Viewer
+	if viewer == nil {
+		var err error
+		viewer, err = LoadViewer()
+		if err != nil { return err }
+	}
+
+	loop, err := StartEventLoop(ctx, EventOptions{Mouse: viewer.Mouse, AlternativeScreen: true})
+	if err != nil { return err }
+	defer loop.Close()
+
+	g.Page = Clamp(g.Page, 0, g.PageCount() - 1)
+	g.message = ""
+	err = g.draw(viewer)
+	if err != nil { return err }
+
+	for event := range loop.Events() {
+		// La distribucion cambia con el tamaño: se mantiene visible la miniatura seleccionada
+		if event.Kind == EventResize {
+			g.Page = Clamp(g.Page, 0, g.PageCount() - 1)
+			if g.Selected >= 0 { g.Page = g.Selected / g.Layout().PerPage() }
+
+			err = g.draw(viewer)
+			if err != nil { return err }
+			continue
+		}
+		if event.Kind != EventInput { continue }
+
+		if mouse, ok := event.Input.(ansi.MouseEvent); ok {
+			if mouse.Button == ansi.MouseLeft && mouse.Action == ansi.MousePress {
+				lastSelected := g.Selected
+				g.selectAt(image.Pt(mouse.X, mouse.Y))
+				if g.Selected != lastSelected {
+					err = g.draw(viewer)
+					if err != nil { return err }
+				}
+			}
+			continue
+		}
+
+		// El mensaje de la linea de estado se quita con la siguiente tecla
+		lastPage, lastSelected, lastMessage := g.Page, g.Selected, g.message
+		g.message = ""
+
+		switch viewer.Action(event.Input) {
+		case Quit:
+			os.Stdout.Write(moveToStart)
+			os.Stdout.Write(eraseScreen_FromCursor)
+			return nil
+		case Next:
+			g.Page = min(g.Page + 1, g.PageCount() - 1)
+		case Prev:
+			g.Page = max(g.Page - 1, 0)
+		case First:
+			g.Page = 0
+		case Last:
+			g.Page = g.PageCount() - 1
+		case PanRight:
+			g.moveSelection(1)
+		case PanLeft:
+			g.moveSelection(-1)
+		case PanDown:
+			g.moveSelection(g.Layout().Columns)
+		case PanUp:
+			g.moveSelection(-g.Layout().Columns)
+		case CopyPath:
+			if g.Selected >= 0 { viewer.copyPath(g.Paths[g.Selected]) }
+		case Open:
+			err = g.open(viewer, loop)
+			if err != nil { return err }
+
+			err = g.draw(viewer)
+			if err != nil { return err }
+			continue
+		}
+
+		if g.Page != lastPage || g.Selected != lastSelected || g.message != lastMessage {
+			err = g.draw(viewer)
+			if err != nil { return err }
+		}
+	}
+
+	return loop.Err()
+}
+
+// selectAt selecciona la miniatura de la pagina actual que contiene la celda 'point'
+func (g *Grid) selectAt(point image.Point) {
+	layout := g.Layout()
+
+	slot := layout.SlotAt(point)
+	if slot < 0 { return }
+
+	index := g.Page * layout.PerPage() + slot
+	if index < len(g.Paths) { g.Selected = index }
+}
+
+// moveSelection mueve la seleccion 'delta' miniaturas y cambia de pagina si hace falta
+// Sin seleccion previa, selecciona la primera miniatura de la pagina actual
+func (g *Grid) moveSelection(delta int) {
+	perPage := g.Layout().PerPage()
+
+	if g.Selected < 0 {
+		g.Selected = min(g.Page * perPage, len(g.Paths) - 1)
+		return
+	}
+
+	g.Selected = Clamp(g.Selected + delta, 0, len(g.Paths) - 1)
+	g.Page = g.Selected / perPage
+}
+
+// open muestra la imagen seleccionada en modo interactivo hasta la accion Quit
+// Mientras tanto el visor usa la ruta de la imagen para la linea de estado, el titulo y CopyPath
+// Si la imagen no se puede cargar se vuelve a la cuadricula con el error en la linea de estado;
+// solo se devuelven los errores del visor (por ejemplo al escribir en el terminal)
+func (g *Grid) open(viewer *Viewer, loop *EventLoop) error {
+	if g.Selected < 0 || g.Selected >= len(g.Paths) { return nil }
+
+	img, err := LoadImage(g.Paths[g.Selected])
+	if err != nil {
+		g.message = fmt.Sprintf(" %s: %v ", filepath.Base(g.Paths[g.Selected]), err)
+		return nil
+	}
+	defer PutReusableRGBA(img)
+
+	size, err := GetTerminalPixelSize()
+	if err != nil { size = cachedPixelSize() }
+
+	interpolator := g.Interpolator
+	if interpolator == nil { interpolator = draw.BiLinear }
+
+	src := newRenderImage(img, image.Rect(0,0, size.X, size.Y), image.Point{}, interpolator, *UI_Settings{}.Default())
+
+	os.Stdout.Write(moveToStart)
+	os.Stdout.Write(eraseScreen_FromCursor)
+
+	file := viewer.File
+	defer func() { viewer.File = file }()
+	viewer.File = g.Paths[g.Selected]
+	defer viewer.pushTitle()()
+
+	return viewer.interact(src, loop)
+}
+
+// draw limpia la pantalla y dibuja la pagina actual y, si lo hay, el mensaje de la linea de estado
+func (g *Grid) draw(viewer *Viewer) error {
+	os.Stdout.Write(moveToStart)
+	os.Stdout.Write(eraseScreen_FromCursor)
+
+	err := g.Print()
+	if err != nil { return err }
+
+	if g.message != "" { writeStatusLine(g.message, viewer.StatusStyle) }
+	return nil
+}
@@ -1,15 +1,8 @@
 package terminal
 
-import (
-	"log"
-)
-
-// initializa el tamaño del terminal y los pools de imágenes reutilizables
+// initializa el tamaño del terminal
 func init() {
-	// determina el tamaño actual del terminal
-	var err error
-	terminalSize, err = GetTerminalPixelSize()
-	if err != nil {log.Fatal(err)}
-
-	asignRGBA_Pools()
+	// determina el tamaño actual del terminal; si no hay terminal (por ejemplo con la
+	// salida redirigida) se usa DefaultTerminalSize hasta que se pueda consultar
+	GetTerminalPixelSize()
 }
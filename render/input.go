@@ -0,0 +1,135 @@
+package terminal
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/Leontas-9/terminal-go/ansi"
+)
+
+// inputSession lee teclas y eventos de raton de la entrada estandar en modo crudo
+// Sustituye a eiannone/keyboard, que no informa de los eventos del raton
+// El modo crudo y el raton se registran en un TerminalState, que los restaura al cerrarse
+// La lectura pertenece a la sesion: al cerrarse deja de leer la entrada estandar (que vuelve
+// a ser del programa) y descarta lo que se leyo y nadie recibio
+type inputSession struct {
+	events	chan inputResult
+	reader	*stdinReader
+	queued	[]ansi.InputEvent
+	done	chan struct{}
+	stopped	chan struct{}
+	once	sync.Once
+}
+
+// inputResult es un evento leido de la entrada estandar, o el error que detuvo la lectura
+type inputResult struct {
+	event	ansi.InputEvent
+	err		error
+}
+
+// errInputClosed es el error con el que termina la lectura al cerrarse la sesion
+var errInputClosed = errors.New("terminal: entrada cerrada")
+
+// Teclas y eventos de raton leidos fuera de un EventLoop (mientras se esperaba la respuesta
+// de una consulta): la siguiente sesion los entrega antes que lo que lea
+var inputQueue struct {
+	mu		sync.Mutex
+	events	[]ansi.InputEvent
+}
+
+// queueInput guarda un evento para la siguiente sesion de entrada
+func queueInput(event ansi.InputEvent) {
+	inputQueue.mu.Lock()
+	defer inputQueue.mu.Unlock()
+
+	inputQueue.events = append(inputQueue.events, event)
+}
+
+// takeQueuedInput devuelve los eventos guardados y vacia la cola
+func takeQueuedInput() []ansi.InputEvent {
+	inputQueue.mu.Lock()
+	defer inputQueue.mu.Unlock()
+
+	events := inputQueue.events
+	inputQueue.events = nil
+	return events
+}
+
+// openInput pasa el terminal a modo crudo, empieza a leer la entrada estandar y, si 'mouse' es
+// verdadero, activa el seguimiento del raton con codificacion SGR (1006)
+// Los tres cambios se deshacen con state.Close, en orden inverso: la lectura termina antes de
+// que el terminal vuelva al modo normal
+func openInput(state *TerminalState, mouse bool) (*inputSession, error) {
+	err := state.RawInput()
+	if err != nil { return nil, err }
+
+	reader, err := newStdinReader()
+	if err != nil { return nil, err }
+
+	in := &inputSession{
+		events:		make(chan inputResult),
+		reader:		reader,
+		queued:		takeQueuedInput(),
+		done:		make(chan struct{}),
+		stopped:	make(chan struct{}),
+	}
+	go in.read()
+	state.Push(in.Close)
+
+	if mouse {
+		err = state.Mouse(true)
+		if err != nil { return nil, err }
+	}
+
+	return in, nil
+}
+
+// read envia a in.events los eventos guardados en la cola y despues decodifica la entrada
+// estandar hasta que se cierra la sesion o falla la lectura (el error tambien se envia)
+func (in *inputSession) read() {
+	defer close(in.stopped)
+
+	for _, event := range in.queued {
+		select {
+		case in.events <- inputResult{event: event}:
+		case <-in.done:
+			return
+		}
+	}
+
+	decoder := ansi.NewInputDecoder(in.reader)
+	for {
+		event, err := decoder.ReadEvent()
+		if errors.Is(err, errInputClosed) { return }
+
+		select {
+		case in.events <- inputResult{event: event, err: err}:
+		case <-in.done:
+			return
+		}
+		if err != nil { return }
+	}
+}
+
+// ReadEvent bloquea hasta recibir el siguiente evento de teclado o de raton
+// Despues de Close devuelve errInputClosed
+func (in *inputSession) ReadEvent() (ansi.InputEvent, error) {
+	select {
+	case result := <-in.events:
+		return result.event, result.err
+	case <-in.done:
+		return nil, errInputClosed
+	}
+}
+
+// Close deja de leer la entrada estandar y espera a que termine la lectura en curso
+// Lo leido y no recibido se descarta; se puede llamar varias veces
+func (in *inputSession) Close() (err error) {
+	in.once.Do(func() {
+		close(in.done)
+		in.reader.cancel()
+		<-in.stopped
+		err = in.reader.close()
+	})
+	return err
+}
@@ -0,0 +1,237 @@
+package terminal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Leontas-9/terminal-go/ansi"
+)
+
+// Action es una accion del visor, independiente de la tecla que la dispara
+type Action int
+
+// Acciones de los modos interactivos (Displacement, Gallery y Grid)
+const (
+	ActionNone Action = iota
+	PanUp
+	PanDown
+	PanLeft
+	PanRight
+	ZoomIn
+	ZoomOut
+	ZoomToFit
+	ZoomActual
+	RotateClockwise
+	RotateCounterClockwise
+	FlipHorizontal
+	FlipVertical
+	Next
+	Prev
+	First
+	Last
+	Open
+	Quit
+	Help
+	CopyPath
+)
+
+// actionNames son los nombres de las acciones en el archivo de configuracion
+var actionNames = [...]string{
+	ActionNone:				"none",
+	PanUp:					"pan-up",
+	PanDown:				"pan-down",
+	PanLeft:				"pan-left",
+	PanRight:				"pan-right",
+	ZoomIn:					"zoom-in",
+	ZoomOut:				"zoom-out",
+	ZoomToFit:				"zoom-fit",
+	ZoomActual:				"zoom-actual",
+	RotateClockwise:		"rotate-cw",
+	RotateCounterClockwise:	"rotate-ccw",
+	FlipHorizontal:			"flip-h",
+	FlipVertical:			"flip-v",
+	Next:					"next",
+	Prev:					"prev",
+	First:					"first",
+	Last:					"last",
+	Open:					"open",
+	Quit:					"quit",
+	Help:					"help",
+	CopyPath:				"copy-path",
+}
+
+// String devuelve el nombre de la accion, por ejemplo "pan-up"
+func (action Action) String() string {
+	if action < 0 || int(action) >= len(actionNames) { return fmt.Sprintf("action(%d)", int(action)) }
+	return actionNames[action]
+}
+
+// ParseAction convierte un nombre ("pan-up", "zoom-in", "quit"...) en su accion
+func ParseAction(name string) (Action, error) {
+	for action, actionName := range actionNames {
+		if actionName == name { return Action(action), nil }
+	}
+
+	return ActionNone, fmt.Errorf("accion desconocida %q", name)
+}
+
+// isPan indica si la accion desplaza la imagen (y por lo tanto acelera al repetirse)
+func (action Action) isPan() bool {
+	return action >= PanUp && action <= PanRight
+}
+
+// KeyMap asocia nombres de teclas (ver ansi.KeyEvent.String: "up", "ctrl+c", "H", "space")
+// con acciones del visor
+type KeyMap map[string]Action
+
+// DefaultKeyMap devuelve la asignacion de teclas por defecto
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		"up":		PanUp,
+		"down":		PanDown,
+		"left":		PanLeft,
+		"right":	PanRight,
+		"+":		ZoomIn,
+		"=":		ZoomIn,
+		"-":		ZoomOut,
+		"0":		ZoomToFit,
+		"1":		ZoomActual,
+		"r":		RotateClockwise,
+		"R":		RotateCounterClockwise,
+		"h":		FlipHorizontal,
+		"v":		FlipVertical,
+		"n":		Next,
+		"pgdn":		Next,
+		"p":		Prev,
+		"pgup":		Prev,
+		"home":		First,
+		"end":		Last,
+		"enter":	Open,
+		"esc":		Quit,
+		"ctrl+c":	Quit,
+		"q":		Quit,
+		"?":		Help,
+		"c":		CopyPath,
+	}
+}
+
+// Presets de teclas que se pueden aplicar sobre el mapa por defecto
+var keyPresets = map[string]KeyMap{
+	// hjkl desplaza como en vim; el espejo horizontal pasa a H
+	"vim": {
+		"h":	PanLeft,
+		"j":	PanDown,
+		"k":	PanUp,
+		"l":	PanRight,
+		"H":	FlipHorizontal,
+	},
+	// WASD desplaza como en los juegos
+	"wasd": {
+		"w":	PanUp,
+		"a":	PanLeft,
+		"s":	PanDown,
+		"d":	PanRight,
+	},
+}
+
+// Lookup devuelve la accion asociada a la tecla, o ActionNone
+func (keys KeyMap) Lookup(key ansi.KeyEvent) Action {
+	return keys[key.String()]
+}
+
+// Merge aplica las asignaciones de 'other' sobre el mapa; ActionNone elimina la tecla
+func (keys KeyMap) Merge(other KeyMap) {
+	for key, action := range other {
+		if action == ActionNone {
+			delete(keys, key)
+			continue
+		}
+		keys[key] = action
+	}
+}
+
+// Preset aplica un preset de teclas ("vim" o "wasd") sobre el mapa
+func (keys KeyMap) Preset(name string) error {
+	preset, ok := keyPresets[name]
+	if !ok { return fmt.Errorf("preset de teclas desconocido %q", name) }
+
+	keys.Merge(preset)
+	return nil
+}
+
+// DefaultConfigPath devuelve la ruta del archivo de configuracion del visor
+// (por ejemplo ~/.config/terminal-go/keys.conf en Linux, %AppData%\terminal-go\keys.conf en Windows)
+func DefaultConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil { return "", err }
+
+	return filepath.Join(dir, "terminal-go", "keys.conf"), nil
+}
+
+// LoadConfig aplica al visor el archivo de configuracion 'path'
+func (v *Viewer) LoadConfig(path string) error {
+	file, err := os.Open(path)
+	if err != nil { return err }
+	defer file.Close()
+
+	err = v.ReadConfig(file)
+	if err != nil { return fmt.Errorf("%s: %w", path, err) }
+
+	return nil
+}
+
+// ReadConfig aplica al visor una configuracion con una asignacion "clave = valor" por linea:
+//
+//	# comentario
+//	preset   = vim          # vim (hjkl) o wasd
+//	step     = 4            # desplazamiento por pulsacion
+//	max-step = 16           # desplazamiento maximo al mantener la tecla
+//	speed    = 150ms        # intervalo maximo entre repeticiones para acelerar
+//	ctrl+q   = quit         # cualquier otra clave es una tecla
+//	x        = none         # elimina la tecla
+func (v *Viewer) ReadConfig(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		text = strings.TrimSpace(text)
+		if text == "" { continue }
+
+		name, value, ok := strings.Cut(text, "=")
+		if !ok { return fmt.Errorf("linea %d: falta '='", line) }
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+
+		err := v.setConfig(name, value)
+		if err != nil { return fmt.Errorf("linea %d: %w", line, err) }
+	}
+
+	return scanner.Err()
+}
+
+// setConfig aplica una asignacion del archivo de configuracion (ver ReadConfig)
+func (v *Viewer) setConfig(name, value string) (err error) {
+	switch name {
+	case "preset":
+		return v.Keys.Preset(value)
+	case "step":
+		v.Step, err = strconv.Atoi(value)
+		return err
+	case "max-step":
+		v.MaxStep, err = strconv.Atoi(value)
+		return err
+	case "speed":
+		v.Speed, err = time.ParseDuration(value)
+		return err
+	}
+
+	action, err := ParseAction(value)
+	if err != nil { return err }
+
+	v.Keys.Merge(KeyMap{name: action})
+	return nil
+}
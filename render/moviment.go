@@ -4,62 +4,24 @@ import (
 	"image"
 	"os"
 	"time"
-
-	"github.com/eiannone/keyboard"
 )
 
-//
+// Displacement muestra la imagen en modo interactivo hasta pulsar Esc, Ctrl+C o q
+// Usa un Viewer con las teclas por defecto y el archivo de configuracion del usuario
+// (ver DefaultConfigPath), si existe
 func (src *RenderImage) Displacement() error {
-	keyboard.Open()
-	defer keyboard.Close()
-
-	os.Stdout.Write(alternativeScreen_On)
-	defer os.Stdout.Write(alternativeScreen_Off)
-
-	_,err := src.Print()
-	if err != nil {return err}
-
-	lastPosition 	:= src.InitialPoint
-	lastScreen		:= terminalSize
-	
-	for {
-		_, actualKey, err := keyboard.GetKey()
-		if err != nil { return err }
-
-		src.moviment(actualKey)
-
-		if actualKey == keyboard.KeyEsc || actualKey == keyboard.KeyCtrlC {
-			os.Stdout.Write(moveToStart)
-			os.Stdout.Write(eraseScreen_FromCursor)
-			return nil
-		}
+	viewer, err := LoadViewer()
+	if err != nil { return err }
 
-		actualScreen, err := GetTerminalSize()
-		if err != nil { return err }
-		
-		if !lastPosition.Eq(src.InitialPoint) {
-			lastPosition = src.InitialPoint
-			src.printNewPosition()
-		}
-		if !lastScreen.Eq(actualScreen) {
-			src.InitialPoint = ClampToPoint(src.InitialPoint, actualScreen)
-			lastScreen = actualScreen
-			src.printNewPosition()
-		}
-	}
+	return viewer.Show(src)
 }
 
-func (src *RenderImage) moviment(actualKey keyboard.Key) {
-	switch actualKey {
-	case keyboard.KeyArrowUp:
-		src.MoveUp(StepsDistance)
-	case keyboard.KeyArrowDown:
-		src.MoveDown(StepsDistance)
-	case keyboard.KeyArrowRight:
-		src.MoveRight(StepsDistance)
-	case keyboard.KeyArrowLeft:
-		src.MoveLeft(StepsDistance)
-	}
+// pan desplaza la vista si la imagen esta ampliada o, si no, la mueve dentro del terminal
+func (src *RenderImage) pan(dx, dy int) bool {
+	if src.IsZoomed() { return src.PanViewport(dx, dy) }
+
+	src.SetInitialPoint(src.InitialPoint.Add(image.Pt(dx, dy)))
+	return false
 }
 
 func (src *RenderImage) printNewPosition() error {
@@ -72,8 +34,11 @@ func (src *RenderImage) printNewPosition() error {
 	return nil
 }
 
+// Valores por defecto de los visores creados con NewViewer
 var( 
+	// Desplazamiento por pulsacion (Viewer.Step)
 	StepsDistance 	= 2
+	// Intervalo maximo entre pulsaciones de la misma tecla para acelerar (Viewer.Speed)
 	StepSpeed 		= 100 * time.Millisecond
 )
 
@@ -91,4 +56,4 @@ func (src *RenderImage) MoveLeft(step int) {
 
 func (src *RenderImage) MoveRight(step int) {
 	src.SetInitialPoint(image.Pt(src.InitialPoint.X+ step, src.InitialPoint.Y))
-}
\ No newline at end of file
+}
@@ -0,0 +1,25 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package terminal
+
+import (
+	"errors"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// waitInput espera a que 'fd' tenga datos (true) o a que se escriba en 'cancel' (false)
+// Usa select y no poll, que en macOS no funciona con los terminales
+func waitInput(fd, cancel int) (ready bool, err error) {
+	var set unix.FdSet
+	if max(fd, cancel) >= 8 * int(unsafe.Sizeof(set)) { return false, errors.New("terminal: descriptor fuera de FD_SETSIZE") }
+
+	set.Set(fd)
+	set.Set(cancel)
+
+	_, err = unix.Select(max(fd, cancel) + 1, &set, nil, nil, nil)
+	if err != nil { return false, err }
+
+	return !set.IsSet(cancel), nil
+}
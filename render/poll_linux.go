@@ -0,0 +1,17 @@
+package terminal
+
+import "golang.org/x/sys/unix"
+
+// waitInput espera a que 'fd' tenga datos (true) o a que se escriba en 'cancel' (false)
+func waitInput(fd, cancel int) (ready bool, err error) {
+	fds := []unix.PollFd{
+		{Fd: int32(fd), Events: unix.POLLIN},
+		{Fd: int32(cancel), Events: unix.POLLIN},
+	}
+
+	_, err = unix.Poll(fds, -1)
+	if err != nil { return false, err }
+
+	// Un error o cierre de 'fd' tambien cuenta como listo: lo informa la lectura
+	return fds[1].Revents == 0, nil
+}
@@ -0,0 +1,204 @@
+package terminal
+
+import (
+	"image"
+	"math/bits"
+	"sync"
+	"sync/atomic"
+)
+
+// Clases de tamaño del pool de imagenes RGBA
+// Cada clase guarda buffers de Pix con capacidad exacta de 1 << shift bytes,
+// desde 4 KiB (32x32 pixeles) hasta 64 MiB (suficiente para un 4K: 3840x2160x4 ≈ 31.6 MiB)
+const (
+	minClassShift = 12
+	maxClassShift = 26
+	numClasses    = maxClassShift - minClassShift + 1
+)
+
+// MaxRetainedRGBA es el maximo de bytes que el pool conserva entre todas las clases.
+// Las imagenes devueltas que superen este limite se descartan y quedan para el GC.
+var MaxRetainedRGBA int64 = 256 << 20
+
+// rgbaClass es una lista libre de imagenes con la misma capacidad de Pix
+type rgbaClass struct {
+	mu		sync.Mutex
+	free	[]*image.RGBA
+}
+
+// rgbaPools almacena una lista libre por clase de tamaño
+var rgbaPools [numClasses]rgbaClass
+
+// Contadores del pool, actualizados de forma atomica
+var rgbaStats struct {
+	hits		atomic.Uint64
+	misses		atomic.Uint64
+	puts		atomic.Uint64
+	drops		atomic.Uint64
+	retained	atomic.Int64
+}
+
+// PoolStats es una instantanea de los contadores del pool de imagenes RGBA
+type PoolStats struct {
+	// Hits cuenta las imagenes entregadas desde un buffer reciclado
+	Hits			uint64
+
+	// Misses cuenta las imagenes que tuvieron que asignarse de nuevo
+	Misses			uint64
+
+	// Puts cuenta las imagenes aceptadas de vuelta en el pool
+	Puts			uint64
+
+	// Drops cuenta las imagenes rechazadas (tamaño fuera de clase, subimagenes o limite de retencion)
+	Drops			uint64
+
+	// BytesRetained es la memoria que el pool conserva en este momento
+	BytesRetained	int64
+}
+
+// GetRGBA_PoolStats devuelve los contadores actuales del pool de imagenes RGBA.
+// Se puede publicar para monitoreo, por ejemplo con expvar.Func.
+func GetRGBA_PoolStats() PoolStats {
+	return PoolStats{
+		Hits:			rgbaStats.hits.Load(),
+		Misses:			rgbaStats.misses.Load(),
+		Puts:			rgbaStats.puts.Load(),
+		Drops:			rgbaStats.drops.Load(),
+		BytesRetained:	rgbaStats.retained.Load(),
+	}
+}
+
+// Obtiene una imagen de tipo RGBA reusable, con sus pixeles a cero
+// La imagen tiene el tamaño de bounds pero siempre empieza en (0,0), aunque bounds.Min no sea cero:
+// asi PutReusableRGBA la distingue de una subimagen
+// Es recomendable que al finalizar su uso se devuelva al pool
+// usando PutReusableRGBA para evitar fugas de memoria.
+func GetReusableRGBA(bounds image.Rectangle) *image.RGBA {
+	bounds = image.Rect(0,0, bounds.Dx(), bounds.Dy())
+	size := bounds.Dx() * bounds.Dy() * BPP
+
+	class, ok := getClass(size)
+	if !ok {
+		rgbaStats.misses.Add(1)
+		return image.NewRGBA(bounds)
+	}
+
+	img := rgbaPools[class].pop()
+	if img == nil {
+		rgbaStats.misses.Add(1)
+		img = &image.RGBA{Pix: make([]byte, 0, classSize(class))}
+	} else {
+		rgbaStats.hits.Add(1)
+		rgbaStats.retained.Add(-int64(cap(img.Pix)))
+	}
+
+	img.Pix		= img.Pix[:size]
+	img.Stride	= bounds.Dx() * BPP
+	img.Rect	= bounds
+
+	return img
+}
+
+// PutReusableRGBA limpia y devuelve una imagen RGBA al pool de reutilización.
+// Esto permite que la imagen sea reutilizada en futuras operaciones,
+// Solo se aceptan imagenes completas, con origen (0,0), cuyo Pix es un buffer del tamaño de
+// una clase (las de GetReusableRGBA y LoadImage); las demas (por ejemplo de image.NewRGBA
+// o subimagenes, que comparten los pixeles de otra imagen) se descartan y quedan para el GC.
+// No se debe seguir usando la imagen despues de devolverla.
+func PutReusableRGBA(img *image.RGBA) {
+	if img == nil { return }
+
+	capacity := cap(img.Pix)
+	class, ok := putClass(capacity)
+	if !ok || !isWholeImage(img) {
+		rgbaStats.drops.Add(1)
+		return
+	}
+
+	if rgbaStats.retained.Add(int64(capacity)) > MaxRetainedRGBA {
+		rgbaStats.retained.Add(-int64(capacity))
+		rgbaStats.drops.Add(1)
+		return
+	}
+
+	clear(img.Pix[:capacity])
+	img.Pix		= img.Pix[:0]
+	img.Stride	= 0
+	img.Rect	= image.Rectangle{}
+
+	rgbaPools[class].push(img)
+	rgbaStats.puts.Add(1)
+}
+
+// isWholeImage indica si la imagen ocupa su Pix completo desde el primer byte, como las
+// que entrega GetReusableRGBA (siempre con origen (0,0)): una subimagen empieza en otro punto
+// del Pix de su padre (Rect.Min distinto de cero) o conserva el largo del padre (len(Pix)
+// mayor que sus pixeles)
+func isWholeImage(img *image.RGBA) bool {
+	return img.Rect.Min == image.Point{} &&
+		img.Stride == img.Rect.Dx()*BPP &&
+		len(img.Pix) == img.Rect.Dx()*img.Rect.Dy()*BPP
+}
+
+// TrimRGBA_Pool descarta todas las imagenes conservadas por el pool
+// y deja que el GC recupere su memoria
+func TrimRGBA_Pool() {
+	for i := range rgbaPools {
+		pool := &rgbaPools[i]
+
+		pool.mu.Lock()
+		for _, img := range pool.free {
+			rgbaStats.retained.Add(-int64(cap(img.Pix)))
+		}
+		clear(pool.free)
+		pool.free = pool.free[:0]
+		pool.mu.Unlock()
+	}
+}
+
+// getClass devuelve la clase mas pequeña cuya capacidad alcanza para size bytes
+func getClass(size int) (class int, ok bool) {
+	shift := minClassShift
+	if size > 1 << minClassShift { shift = bits.Len(uint(size - 1)) }
+	if shift > maxClassShift { return 0, false }
+
+	return shift - minClassShift, true
+}
+
+// putClass devuelve la clase de un buffer cuya capacidad es exactamente la de una clase
+// Cualquier otra capacidad indica que el buffer no viene del pool o no empieza en su inicio
+func putClass(capacity int) (class int, ok bool) {
+	if capacity < 1 << minClassShift || capacity & (capacity - 1) != 0 { return 0, false }
+
+	shift := bits.Len(uint(capacity)) - 1
+	if shift > maxClassShift { return 0, false }
+
+	return shift - minClassShift, true
+}
+
+// classSize devuelve la capacidad en bytes de una clase
+func classSize(class int) int {
+	return 1 << (class + minClassShift)
+}
+
+// pop saca una imagen de la lista libre, o nil si esta vacia
+func (pool *rgbaClass) pop() (img *image.RGBA) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	last := len(pool.free) - 1
+	if last < 0 { return nil }
+
+	img = pool.free[last]
+	pool.free[last] = nil
+	pool.free = pool.free[:last]
+
+	return img
+}
+
+// push agrega una imagen a la lista libre
+func (pool *rgbaClass) push(img *image.RGBA) {
+	pool.mu.Lock()
+	pool.free = append(pool.free, img)
+	pool.mu.Unlock()
+}
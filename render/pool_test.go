@@ -0,0 +1,178 @@
+package terminal
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// poolDelta devuelve cuanto cambiaron los contadores del pool desde 'before'
+func poolDelta(before PoolStats) PoolStats {
+	after := GetRGBA_PoolStats()
+	return PoolStats{
+		Hits:			after.Hits - before.Hits,
+		Misses:			after.Misses - before.Misses,
+		Puts:			after.Puts - before.Puts,
+		Drops:			after.Drops - before.Drops,
+		BytesRetained:	after.BytesRetained - before.BytesRetained,
+	}
+}
+
+func TestPoolCounters(t *testing.T) {
+	TrimRGBA_Pool()
+	before := GetRGBA_PoolStats()
+
+	// 50x50x4 = 10000 bytes -> clase de 16 KiB
+	img := GetReusableRGBA(image.Rect(0,0, 50, 50))
+	if cap(img.Pix) != 16 << 10 { t.Fatalf("cap(Pix) = %d, se esperaba %d", cap(img.Pix), 16 << 10) }
+	if len(img.Pix) != 50*50*BPP || img.Stride != 50*BPP { t.Fatalf("len(Pix) = %d, Stride = %d", len(img.Pix), img.Stride) }
+
+	PutReusableRGBA(img)
+	again := GetReusableRGBA(image.Rect(0,0, 60, 40))
+	for i, b := range again.Pix {
+		if b != 0 { t.Fatalf("Pix[%d] = %d: el pool entrego una imagen sin limpiar", i, b) }
+	}
+	PutReusableRGBA(again)
+
+	// Fuera de clase (menos de 4 KiB) y sin capacidad de clase (image.NewRGBA)
+	PutReusableRGBA(image.NewRGBA(image.Rect(0,0, 30, 30)))
+	PutReusableRGBA(image.NewRGBA(image.Rect(0,0, 100, 100)))
+	PutReusableRGBA(nil)
+
+	got := poolDelta(before)
+	want := PoolStats{Hits: 1, Misses: 1, Puts: 2, Drops: 2, BytesRetained: 16 << 10}
+	if got != want { t.Errorf("contadores = %+v, se esperaba %+v", got, want) }
+
+	TrimRGBA_Pool()
+	if retained := GetRGBA_PoolStats().BytesRetained; retained != 0 { t.Errorf("BytesRetained = %d despues de TrimRGBA_Pool", retained) }
+}
+
+func TestPoolMaxRetained(t *testing.T) {
+	TrimRGBA_Pool()
+	defer func(limit int64) { MaxRetainedRGBA = limit; TrimRGBA_Pool() }(MaxRetainedRGBA)
+
+	const size = 32 << 10
+	MaxRetainedRGBA = 2 * size
+
+	// 64x128x4 = 32 KiB exactos
+	images := make([]*image.RGBA, 3)
+	for i := range images { images[i] = GetReusableRGBA(image.Rect(0,0, 64, 128)) }
+
+	before := GetRGBA_PoolStats()
+	for _, img := range images { PutReusableRGBA(img) }
+
+	got := poolDelta(before)
+	if got.Puts != 2 || got.Drops != 1 { t.Errorf("Puts = %d, Drops = %d, se esperaba 2 y 1", got.Puts, got.Drops) }
+	if retained := GetRGBA_PoolStats().BytesRetained; retained != MaxRetainedRGBA {
+		t.Errorf("BytesRetained = %d, se esperaba %d", retained, MaxRetainedRGBA)
+	}
+}
+
+// TestPoolRejectsSubImages comprueba que una subimagen no limpia ni recicla los pixeles de su padre
+func TestPoolRejectsSubImages(t *testing.T) {
+	TrimRGBA_Pool()
+
+	// 64x64x4 = 16 KiB: la capacidad del padre es exactamente la de su clase
+	parent := GetReusableRGBA(image.Rect(0,0, 64, 64))
+	for i := range parent.Pix { parent.Pix[i] = 0xff }
+
+	subImages := []image.Rectangle{
+		image.Rect(0,0, 64, 32),	// filas superiores: mismo inicio y capacidad que el padre
+		image.Rect(0,32, 64, 64),	// filas inferiores de ancho completo
+		image.Rect(8,8, 40, 40),
+	}
+
+	before := GetRGBA_PoolStats()
+	for _, rect := range subImages { PutReusableRGBA(parent.SubImage(rect).(*image.RGBA)) }
+
+	got := poolDelta(before)
+	if got.Puts != 0 || got.Drops != uint64(len(subImages)) { t.Errorf("Puts = %d, Drops = %d, se esperaba 0 y %d", got.Puts, got.Drops, len(subImages)) }
+
+	for i, b := range parent.Pix {
+		if b != 0xff { t.Fatalf("Pix[%d] = %d: una subimagen limpio los pixeles del padre", i, b) }
+	}
+
+	other := GetReusableRGBA(image.Rect(0,0, 64, 64))
+	if sharesPixels(other, parent) { t.Error("el pool entrego los pixeles de una imagen en uso") }
+
+	PutReusableRGBA(other)
+	PutReusableRGBA(parent)
+	TrimRGBA_Pool()
+}
+
+// TestPoolOffsetBounds comprueba que una imagen pedida con un origen distinto de (0,0)
+// se entrega con origen (0,0) y se recicla al devolverla
+func TestPoolOffsetBounds(t *testing.T) {
+	TrimRGBA_Pool()
+
+	// 40x30x4 = 4800 bytes -> clase de 8 KiB
+	img := GetReusableRGBA(image.Rect(10, 20, 50, 50))
+	if img.Rect != image.Rect(0,0, 40, 30) { t.Fatalf("Rect = %v, se esperaba (0,0)-(40,30)", img.Rect) }
+
+	before := GetRGBA_PoolStats()
+	PutReusableRGBA(img)
+	again := GetReusableRGBA(image.Rect(-5, -5, 35, 25))
+
+	got := poolDelta(before)
+	if got.Puts != 1 || got.Drops != 0 || got.Hits != 1 { t.Errorf("Puts = %d, Drops = %d, Hits = %d, se esperaba 1, 0 y 1", got.Puts, got.Drops, got.Hits) }
+
+	PutReusableRGBA(again)
+	TrimRGBA_Pool()
+}
+
+// TestConvertToRGBA_Offset comprueba que la conversion copia los pixeles de una imagen con
+// origen distinto de (0,0) en una imagen del pool con origen (0,0)
+func TestConvertToRGBA_Offset(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(7, 3, 47, 33))
+	for y := src.Rect.Min.Y; y < src.Rect.Max.Y; y++ {
+		for x := src.Rect.Min.X; x < src.Rect.Max.X; x++ {
+			src.SetNRGBA(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 9, A: 255})
+		}
+	}
+
+	dst, err := ConvertToRGBA(src)
+	if err != nil { t.Fatal(err) }
+	if dst.Rect != image.Rect(0,0, 40, 30) { t.Fatalf("Rect = %v, se esperaba (0,0)-(40,30)", dst.Rect) }
+
+	for y := range 30 {
+		for x := range 40 {
+			want := color.RGBA{R: uint8(x + 7), G: uint8(y + 3), B: 9, A: 255}
+			if got := dst.RGBAAt(x, y); got != want { t.Fatalf("pixel (%d,%d) = %v, se esperaba %v", x, y, got, want) }
+		}
+	}
+
+	before := GetRGBA_PoolStats()
+	PutReusableRGBA(dst)
+	if got := poolDelta(before); got.Puts != 1 { t.Errorf("Puts = %d, Drops = %d: la imagen convertida no se recicla", got.Puts, got.Drops) }
+	TrimRGBA_Pool()
+}
+
+// TestLoadImageOwned comprueba que la imagen de LoadImage siempre sale del pool, tambien
+// cuando el decodificador ya devuelve una imagen RGBA (un PNG opaco)
+func TestLoadImageOwned(t *testing.T) {
+	// PNG opaco de 13x7: png.Decode devuelve *image.RGBA con una capacidad que no es de ninguna clase
+	opaque := testImage(13, 7, 1)
+	path := filepath.Join(t.TempDir(), "opaca.png")
+	file, err := os.Create(path)
+	if err != nil { t.Fatal(err) }
+	if err := png.Encode(file, opaque); err != nil { t.Fatal(err) }
+	if err := file.Close(); err != nil { t.Fatal(err) }
+
+	for _, path := range []string{"../image/man.jpg", path} {
+		img, err := LoadImage(path)
+		if err != nil { t.Fatal(err) }
+		if img.Rect.Min != (image.Point{}) { t.Errorf("%s: origen %v, se esperaba (0,0)", path, img.Rect.Min) }
+		if filepath.Ext(path) == ".png" && !bytes.Equal(img.Pix[:len(opaque.Pix)], opaque.Pix) { t.Errorf("%s: pixeles distintos", path) }
+
+		before := GetRGBA_PoolStats()
+		PutReusableRGBA(img)
+
+		got := poolDelta(before)
+		if got.Puts != 1 || got.Drops != 0 { t.Errorf("%s: Puts = %d, Drops = %d: la imagen no venia del pool", path, got.Puts, got.Drops) }
+	}
+	TrimRGBA_Pool()
+}
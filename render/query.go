@@ -0,0 +1,193 @@
+package terminal
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"os"
+	"time"
+
+	"github.com/Leontas-9/terminal-go/ansi"
+)
+
+// QueryTimeout es el tiempo maximo de espera de las respuestas del terminal
+var QueryTimeout = 200 * time.Millisecond
+
+// Errores de las consultas al terminal
+var (
+	ErrQueryTimeout		= errors.New("terminal: sin respuesta a la consulta")
+	ErrQueryUnsupported	= errors.New("terminal: consulta no soportada")
+	ErrQueryEventLoop	= errors.New("terminal: consulta con un EventLoop activo")
+)
+
+// TerminalInfo reune las respuestas de QueryTerminal; lo que el terminal no
+// respondio queda en cero
+type TerminalInfo struct {
+	// Posicion del cursor: columna y fila desde 1 (como MoveTo)
+	Cursor			image.Point
+
+	// Colores por defecto del texto y del fondo (alfa 0 si no respondio)
+	Foreground		color.RGBA
+	Background		color.RGBA
+
+	// Tamaño en pixeles del area de texto y de una celda
+	WindowPixels	image.Point
+	CellPixels		image.Point
+}
+
+// CellAspect devuelve la relacion alto/ancho de una celda en pixeles (normalmente cerca de 2)
+// Sin CellPixels la calcula con WindowPixels y el tamaño del terminal; 0 si no se conoce
+func (info TerminalInfo) CellAspect() float64 {
+	cell := info.CellPixels
+	if cell.X <= 0 || cell.Y <= 0 {
+		size, err := GetTerminalSize()
+		if err != nil || size.X <= 0 || size.Y <= 0 { return 0 }
+
+		cell = image.Pt(info.WindowPixels.X / size.X, info.WindowPixels.Y / size.Y)
+		if cell.X <= 0 || cell.Y <= 0 { return 0 }
+	}
+
+	return float64(cell.Y) / float64(cell.X)
+}
+
+// QueryTerminal hace todas las consultas a la vez (posicion del cursor, colores y tamaños en
+// pixeles) y espera las respuestas hasta 'timeout' (0 -> QueryTimeout)
+// Devuelve ErrQueryTimeout solo si el terminal no respondio nada y, como el resto de consultas,
+// ErrQueryEventLoop con un EventLoop activo
+func QueryTerminal(timeout time.Duration) (info TerminalInfo, err error) {
+	requests := ansi.RequestCursorPosition() + ansi.RequestForegroundColor() + ansi.RequestBackgroundColor() +
+		ansi.RequestWindowPixels() + ansi.RequestCellPixels()
+
+	err = query(requests, timeout, func(token ansi.Token) {
+		if position, ok := ansi.ParseCursorPosition(token); ok		{ info.Cursor = position }
+		if c, ok := ansi.ParseColorReply(token, 10); ok				{ info.Foreground = c }
+		if c, ok := ansi.ParseColorReply(token, 11); ok				{ info.Background = c }
+		if size, ok := ansi.ParsePixelsReply(token, 4); ok			{ info.WindowPixels = size }
+		if size, ok := ansi.ParsePixelsReply(token, 6); ok			{ info.CellPixels = size }
+	})
+	return info, err
+}
+
+// QueryCursorPosition devuelve la posicion del cursor: columna y fila desde 1 (como MoveTo)
+func QueryCursorPosition() (position image.Point, err error) {
+	found := false
+	err = query(ansi.RequestCursorPosition(), QueryTimeout, func(token ansi.Token) {
+		if reply, ok := ansi.ParseCursorPosition(token); ok { position, found = reply, true }
+	})
+	return position, queryResult(found, err)
+}
+
+// QueryForeground devuelve el color por defecto del texto (OSC 10)
+func QueryForeground() (foreground color.RGBA, err error) {
+	found := false
+	err = query(ansi.RequestForegroundColor(), QueryTimeout, func(token ansi.Token) {
+		if reply, ok := ansi.ParseColorReply(token, 10); ok { foreground, found = reply, true }
+	})
+	return foreground, queryResult(found, err)
+}
+
+// QueryBackground devuelve el color por defecto del fondo (OSC 11)
+func QueryBackground() (background color.RGBA, err error) {
+	found := false
+	err = query(ansi.RequestBackgroundColor(), QueryTimeout, func(token ansi.Token) {
+		if reply, ok := ansi.ParseColorReply(token, 11); ok { background, found = reply, true }
+	})
+	return background, queryResult(found, err)
+}
+
+// QueryCellPixels devuelve el tamaño en pixeles de una celda (ancho y alto)
+func QueryCellPixels() (size image.Point, err error) {
+	found := false
+	err = query(ansi.RequestCellPixels(), QueryTimeout, func(token ansi.Token) {
+		if reply, ok := ansi.ParsePixelsReply(token, 6); ok { size, found = reply, true }
+	})
+	return size, queryResult(found, err)
+}
+
+// QueryWindowPixels devuelve el tamaño en pixeles del area de texto de la ventana
+func QueryWindowPixels() (size image.Point, err error) {
+	found := false
+	err = query(ansi.RequestWindowPixels(), QueryTimeout, func(token ansi.Token) {
+		if reply, ok := ansi.ParsePixelsReply(token, 4); ok { size, found = reply, true }
+	})
+	return size, queryResult(found, err)
+}
+
+// QueryMode devuelve el estado de un modo privado (DECRQM), por ejemplo 1049 o ansi.MouseSGR
+func QueryMode(mode int) (state ansi.ModeState, err error) {
+	found := false
+	err = query(ansi.RequestMode(mode), QueryTimeout, func(token ansi.Token) {
+		if replyMode, replyState, ok := ansi.ParseModeReply(token); ok && replyMode == mode {
+			state, found = replyState, true
+		}
+	})
+	return state, queryResult(found, err)
+}
+
+// queryResult devuelve ErrQueryUnsupported si el terminal respondio (sin error) pero no a la consulta
+func queryResult(found bool, err error) error {
+	if err == nil && !found { return ErrQueryUnsupported }
+	return err
+}
+
+// query envia las consultas seguidas de DA1 y pasa cada respuesta a 'handle' hasta que llega
+// la de DA1 (todas las anteriores ya llegaron) o pasa 'timeout' (0 -> QueryTimeout)
+// Pone el terminal en modo crudo mientras espera; las teclas y eventos de raton que lleguen
+// entre tanto se guardan para la siguiente sesion de entrada (ver queueInput)
+// Con un EventLoop activo devuelve ErrQueryEventLoop: el bucle se quedaria con las respuestas
+func query(requests string, timeout time.Duration, handle func(ansi.Token)) error {
+	if activeLoops.Load() > 0 { return ErrQueryEventLoop }
+	if timeout <= 0 { timeout = QueryTimeout }
+
+	state := NewTerminalState()
+	defer state.Recover()
+
+	input, err := openInput(state, false)
+	if err != nil { return err }
+
+	_, err = os.Stdout.WriteString(requests + ansi.RequestDeviceAttributes())
+	if err != nil { return err }
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	answered := false
+	for {
+		select {
+		case result := <-input.events:
+			if result.err != nil { return result.err }
+
+			token, ok := reportToken(result.event)
+			if !ok {
+				queueInput(result.event)
+				continue
+			}
+			if ansi.IsDeviceAttributes(token) { return nil }
+
+			answered = true
+			handle(token)
+		case <-deadline.C:
+			if answered { return nil }
+			return ErrQueryTimeout
+		}
+	}
+}
+
+// reportToken devuelve la respuesta del terminal que contiene el evento
+// Una posicion del cursor en la fila 1 y columnas 2 a 8 llega igual que F3 con
+// modificadores (ESC [ 1 ; m R) y el decodificador la entrega como tecla
+func reportToken(event ansi.InputEvent) (ansi.Token, bool) {
+	switch event := event.(type) {
+	case ansi.ReportEvent:
+		return event.Token(), true
+	case ansi.KeyEvent:
+		if event.Key != ansi.KeyF3 || event.Mod == 0 { break }
+
+		return ansi.Token{
+			Kind:	ansi.TokenCSI,
+			Final:	'R',
+			Params:	[]ansi.Param{{Value: 1}, {Value: int(event.Mod) + 1}},
+		}, true
+	}
+	return ansi.Token{}, false
+}
@@ -0,0 +1,60 @@
+package terminal
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Leontas-9/terminal-go/ansi"
+)
+
+// TestQueryEventLoop comprueba que las consultas fallan sin escribir nada con un EventLoop activo
+func TestQueryEventLoop(t *testing.T) {
+	activeLoops.Add(1)
+	defer activeLoops.Add(-1)
+
+	_, err := QueryCursorPosition()
+	if !errors.Is(err, ErrQueryEventLoop) { t.Errorf("QueryCursorPosition: %v, se esperaba ErrQueryEventLoop", err) }
+
+	_, err = QueryTerminal(0)
+	if !errors.Is(err, ErrQueryEventLoop) { t.Errorf("QueryTerminal: %v, se esperaba ErrQueryEventLoop", err) }
+}
+
+// TestInputQueue comprueba que los eventos guardados se entregan una sola vez y en orden
+func TestInputQueue(t *testing.T) {
+	takeQueuedInput()
+
+	events := []ansi.InputEvent{
+		ansi.KeyEvent{Rune: 'a'},
+		ansi.MouseEvent{X: 3, Y: 4},
+		ansi.KeyEvent{Key: ansi.KeyF3},
+	}
+	for _, event := range events { queueInput(event) }
+
+	got := takeQueuedInput()
+	if len(got) != len(events) { t.Fatalf("%d eventos, se esperaban %d", len(got), len(events)) }
+	for i := range events {
+		if got[i] != events[i] { t.Errorf("evento %d = %#v, se esperaba %#v", i, got[i], events[i]) }
+	}
+
+	if again := takeQueuedInput(); len(again) != 0 { t.Errorf("la cola no se vacio: %v", again) }
+}
+
+// TestReportToken comprueba que solo las respuestas (y F3 con modificadores, que puede ser
+// la posicion del cursor) se tratan como respuestas de una consulta
+func TestReportToken(t *testing.T) {
+	tests := []struct {
+		event	ansi.InputEvent
+		report	bool
+	}{
+		{ansi.KeyEvent{Rune: 'q'}, false},
+		{ansi.KeyEvent{Key: ansi.KeyF3}, false},
+		{ansi.KeyEvent{Key: ansi.KeyF3, Mod: ansi.ModShift}, true},
+		{ansi.MouseEvent{X: 1, Y: 1}, false},
+	}
+
+	for _, test := range tests {
+		if _, report := reportToken(test.event); report != test.report {
+			t.Errorf("%#v: respuesta = %v, se esperaba %v", test.event, report, test.report)
+		}
+	}
+}
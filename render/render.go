@@ -6,45 +6,90 @@ import (
 	"fmt"
 	"image"
 	"image/color"
+	"io"
 	"math"
 	"os"
-	"sync"
+	"slices"
+	"strings"
+	"sync/atomic"
 
 	"github.com/Leontas-9/terminal-go/ansi"
-
-	"golang.org/x/image/draw"
-	"golang.org/x/sys/windows"
 )
 
-// DefaultTerminalSize es el tamaño por defecto del terminal, usado para ajustar los bordes de la imagen
+// DefaultTerminalSize es el tamaño por defecto del terminal (columnas y filas), usado para ajustar
+// los bordes de la imagen mientras no se conoce el real (por ejemplo con la salida redirigida)
 var DefaultTerminalSize = image.Point{ X: 141, Y: 58 }
 
 // Print imprime directamente la imagenen el terminal
+// Usa un buffer del pool que se libera en cuanto termina la escritura
 func (src *RenderImage) Print() (lenght int, err error) {
-	bytes,_,err := src.GetPNG()
-	if err != nil { return 0, err }
-
-	return os.Stdout.Write(bytes)
+	n, err := src.WriteTo(os.Stdout)
+	return int(n), err
 }
 
 // GetPNG obtiene la imagen en formato ANSI/ASCII, la imagen reajustada y error si que hay alguno
+// Los bytes devueltos son una copia propia del llamador y siguen siendo validos tras otros renderizados.
+// Si la imagen reajustada no comparte pixeles con src.Image (no es la original ni un recorte suyo),
+// el llamador puede devolverla con PutReusableRGBA
 func (src *RenderImage) GetPNG() (ASCII_Image []byte, image image.Image, err error) {
-	dst := *src
-	err = dst.validateInputs()
+	blocks := GetRenderBuffer()
+	defer PutRenderBuffer(blocks)
+
+	image, err = src.AppendPNG(blocks)
 	if err != nil {return nil, nil, err}
 
+	return bytes.Clone(blocks.Bytes()), image, nil
+}
+
+// AppendPNG renderiza la imagen en formato ANSI/ASCII al final de un buffer del llamador
+// y devuelve la imagen reajustada. El buffer nunca se devuelve al pool desde aqui:
+// quien lo suministra decide cuando liberarlo (por ejemplo con PutRenderBuffer)
+func (src *RenderImage) AppendPNG(blocks *bytes.Buffer) (image image.Image, err error) {
+	dst, err := src.prepareRender()
+	if err != nil {return nil, err}
+
+	err = dst.renderTo(blocks)
+	if err != nil {return nil, err}
+
+	return dst.Image, nil
+}
+
+// WriteTo renderiza la imagen y la escribe en w usando un buffer del pool.
+// El buffer y la imagen reajustada se liberan al terminar la escritura,
+// por lo que ningun byte del pool queda en manos del llamador
+func (src *RenderImage) WriteTo(w io.Writer) (n int64, err error) {
+	blocks := GetRenderBuffer()
+	defer PutRenderBuffer(blocks)
+
+	dst, err := src.prepareRender()
+	if err != nil {return 0, err}
+	if !sharesPixels(dst.Image, src.Image) { defer PutReusableRGBA(dst.Image) }
+
+	err = dst.renderTo(blocks)
+	if err != nil {return 0, err}
+
+	return blocks.WriteTo(w)
+}
+
+// prepareRender obtiene una copia de RenderImage con los bordes y la imagen
+// ya ajustados al terminal, lista para renderizarse
+func (src *RenderImage) prepareRender() (dst RenderImage, err error) {
+	dst = *src
+	err = dst.validateInputs()
+	if err != nil {return dst, err}
+
 	dst.Margin, err = dst.AdjustLimitsToTerminal()
-	if err != nil {return nil, nil, err}
+	if err != nil {return dst, err}
 
 	dst.Image, err = dst.AdjustImage()
-	if err != nil {return nil, nil, err}
+	if err != nil {return dst, err}
 
-	dst.InitialPoint = ClampToPoint(dst.InitialPoint, terminalSize.Sub(dst.Image.Rect.Max))
+	if !dst.ShadeTransparency { dst.Image = dst.compositeImage(src.Image) }
 
-	ASCII_Image, err = dst.RenderImage()
-	if err != nil {return nil, nil, err}
+	if dst.opts.Inline { dst.InitialPoint.Y = 0 }
+	dst.InitialPoint = ClampToPoint(dst.InitialPoint, cachedPixelSize().Sub(dst.Image.Rect.Size()))
 
-	return ASCII_Image, dst.Image, err
+	return dst, nil
 }
 
 // validateInputs Valida los inputs de RenderImage
@@ -58,9 +103,13 @@ func (src *RenderImage) validateInputs() error {
 }
 
 // AdjustLimitsToTerminal Ajusta los bordes de imagen a los bordes del terminal
+// Si no hay terminal (por ejemplo con la salida redirigida) usa el ultimo tamaño conocido
 func (src *RenderImage) AdjustLimitsToTerminal() (newBounds image.Rectangle, err error) {
 	terminalSize, err := GetTerminalPixelSize()
-	if err != nil { return image.Rectangle{}, err }
+	if err != nil { terminalSize = cachedPixelSize() }
+
+	// En modo Inline el cursor queda en la fila siguiente a la imagen, que tambien debe verse
+	if src.opts.Inline { terminalSize.Y = max(terminalSize.Y - PPB, PPB) }
 
 	newBounds	= src.clampToBounds(image.Rect(0,0, terminalSize.X, terminalSize.Y))
 	return newBounds, nil
@@ -94,17 +143,39 @@ func Clamp(value, min, max int) int {
 }
 
 // AdjustImage Ajusta la imagen a una escala acorde a los bordes
+// Si con el Zoom actual la imagen no cabe en los bordes, solo se escala la
+// porcion visible (ver Viewport). La transformacion (giros, espejos) se aplica antes de escalar
 func (src *RenderImage) AdjustImage() (*image.RGBA, error) {
+	if !src.Transform.IsIdentity() {
+		view := *src
+		view.Image = src.Transform.Apply(src.Image, src.SourceBounds())
+		view.Crop = image.Rectangle{}
+		view.Transform = Transform{}
+
+		dst, err := view.AdjustImage()
+		if !sharesPixels(dst, view.Image) { PutReusableRGBA(view.Image) }
+
+		return dst, err
+	}
+
     scale := src.CalculateScale()
-    dst := src.ScaleImage(scale)
+	if !src.isPannable(scale) { return src.ScaleImage(scale), nil }
 
-	return dst, nil
+	return src.ScaleViewport(scale), nil
 }
 
-// CalculateScale Calcula la escala minima para estar dentro de los limites
+// CalculateScale Calcula la escala a aplicar: la de Zoom si esta fijada,
+// o la escala minima para estar dentro de los limites
 func (src *RenderImage) CalculateScale() (scale float64) {
-	srcWidth	:= float64(src.Image.Bounds().Dx())
-	srcHeight	:= float64(src.Image.Bounds().Dy())
+	if src.Zoom > 0 { return src.Zoom }
+
+	return src.fitScale()
+}
+
+// fitScale Calcula la escala minima para estar dentro de los limites
+func (src *RenderImage) fitScale() (scale float64) {
+	srcWidth	:= float64(src.sourceSize().X)
+	srcHeight	:= float64(src.sourceSize().Y)
 
 	scale = math.Min(
 		float64(src.Margin.Dx())/srcWidth,
@@ -114,74 +185,109 @@ func (src *RenderImage) CalculateScale() (scale float64) {
 	return scale
 }
 
-//  ScaleImage  hace el escalamiento de la imagen
+//  ScaleImage  hace el escalamiento de la imagen (o del recorte, ver Crop)
+// Las imagenes grandes se escalan por bandas en paralelo (ver Workers) y las reducciones
+// enteras exactas con BiLinear o CatmullRom se promedian por area (ver scaleInterpolator)
+// A escala 1 no se copia nada: se devuelve la imagen original o una subimagen del recorte
 func (src *RenderImage) ScaleImage(scale float64) (*image.RGBA) {
+	bounds := src.SourceBounds()
+
 	if scale != 1.0 {
-		dst := GetReusableRGBA(image.Rect(0,0, 
-			int(math.Round(float64(src.Image.Rect.Dx())* scale)), 
-			int(math.Round(float64(src.Image.Rect.Dy())* scale)),
-		))
+		size := src.scaledSize(scale)
+		dst := GetReusableRGBA(image.Rect(0,0, size.X, size.Y))
 
-		src.Interpolator.Scale(
-			dst, dst.Bounds(), src.Image, src.Image.Bounds(),draw.Over, nil)
+		scaleRGBA(dst, dst.Bounds(), src.Image, bounds, src.scaleInterpolator(dst.Bounds(), bounds),
+			src.scaleWorkers(dst.Bounds(), bounds))
 
 		return dst
 	}
 
-	return src.Image
+	if bounds.Eq(src.Image.Rect) { return src.Image }
+
+	return src.Image.SubImage(bounds).(*image.RGBA)
+}
+
+// SourceBounds devuelve la region de la imagen original que se renderiza:
+// el recorte (Crop) limitado a la imagen, o la imagen completa si no hay recorte
+func (src *RenderImage) SourceBounds() image.Rectangle {
+	if src.Crop.Empty() { return src.Image.Rect }
+
+	crop := src.Crop.Intersect(src.Image.Rect)
+	if crop.Empty() { return src.Image.Rect }
+
+	return crop
+}
+
+// sharesPixels indica si dos imagenes usan el mismo arreglo de pixeles
+// (por ejemplo una imagen y una subimagen suya)
+func sharesPixels(a, b *image.RGBA) bool {
+	if cap(a.Pix) == 0 || cap(b.Pix) == 0 { return false }
+
+	return &a.Pix[:cap(a.Pix)][cap(a.Pix)-1] == &b.Pix[:cap(b.Pix)][cap(b.Pix)-1]
 }
 
 // RenderImage realiza la transformacion de imagenes a texto ([]byte) Unicode/ANSI
+// Los bytes devueltos pertenecen al llamador (no comparten memoria con el pool)
 func (src *RenderImage) RenderImage() (ASCII_Image []byte, err error) {
-	blocks := bufferPool.Get().(*bytes.Buffer)
-	defer bufferPool.Put(blocks)
-	blocks.Reset()
+	blocks := GetRenderBuffer()
+	defer PutRenderBuffer(blocks)
 
-	err = src.initializeRender(blocks)
+	err = src.renderTo(blocks)
 	if  err != nil { return nil, err }
+
+	return bytes.Clone(blocks.Bytes()), nil
+}
+
+// renderTo escribe la imagen en formato Unicode/ANSI al final del buffer
+func (src *RenderImage) renderTo(blocks *bytes.Buffer) (err error) {
+	start := blocks.Len()
+
+	err = src.initializeRender(blocks)
+	if  err != nil { return err }
 	
 	err = src.renderBlocks(blocks)
-	if  err != nil { return nil, err }
+	if  err != nil { return err }
 
 	err = src.finalizeRender(blocks)
-	if  err != nil { return nil, err }
+	if  err != nil { return err }
 
-	ASCII_Image = blocks.Bytes()
-	if len(ASCII_Image) == 0 {
-		return nil, fmt.Errorf("renderizado vacio, verifique la imagen y los bordes")
+	if blocks.Len() == start {
+		return fmt.Errorf("renderizado vacio, verifique la imagen y los bordes")
 	}
 
-	return ASCII_Image, err
+	return nil
 }
 
-// validateUI_Settings guarda en un buffer las configuraciones de UI si es true, 
-// si es falso este dejara las configuraciones por defecto
-func (src *UI_Settings) validateUI_Settings(buf *bytes.Buffer, isDefaultSettings bool) (err error) {
-isCursor := src.ShowCursor
-	isScreen := src.AlternativeScreen
-	isErase  := src.EraseScreen
-	isWrap   := src.Auto_Wrap
+// GetRenderBuffer obtiene un buffer reutilizable para AppendPNG.
+// Al terminar de usar su contenido se debe devolver con PutRenderBuffer;
+// despues de devolverlo, sus bytes ya no deben leerse.
+func GetRenderBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
 
-	if isDefaultSettings {
-		settings := UI_Settings{}.Default()
-		isCursor  = settings.ShowCursor
-		isScreen  = settings.AlternativeScreen
-		isErase   = settings.EraseScreen
-		isWrap	  = settings.Auto_Wrap
-	}
+	return buf
+}
 
-	buf.Grow(6+ 8+ 5)		// espacio para 3 codigos ANSI 6 -> ShowCursor, 8 -> AlternativeScreen, 5 -> Auto_Wrap
-	_,err = buf.WriteString(ansi.ShowCursor(isCursor))
-	if err != nil { return err }
-	
-	_,err = buf.WriteString(ansi.AlternativeScreen(isScreen))
-	if err != nil { return err }
+// PutRenderBuffer devuelve un buffer obtenido con GetRenderBuffer al pool
+func PutRenderBuffer(buf *bytes.Buffer) {
+	if buf == nil { return }
 
+	bufferPool.Put(buf)
+}
 
-	_,err = buf.WriteString(ansi.Auto_Wrap(isWrap))
-	if err != nil { return err }
-	
-	if isErase {
+// validateUI_Settings guarda en un buffer los modos que la configuracion cambia si 'restore' es falso,
+// o los que los devuelven a su estado anterior si es verdadero (no a los valores por defecto)
+func (src *UI_Settings) validateUI_Settings(buf *bytes.Buffer, restore bool) (err error) {
+	modes := src.changedModes()
+	if restore { slices.Reverse(modes) }
+
+	for _, mode := range modes {
+		// Al renderizar se invierte el valor actual del modo; al restaurar se vuelve a el
+		_,err = buf.WriteString(ansi.PrivateMode(mode, terminalMode(mode) == restore))
+		if err != nil { return err }
+	}
+
+	if src.EraseScreen && !src.Inline && !restore {
 		buf.Grow(3+ 4)		// espacio para 2 codigos ANSI 3 -> MoveToStart, 4 -> EraseScreen_FromCursor
 		_,err = buf.Write(moveToStart)
 		if err != nil { return err }
@@ -193,39 +299,87 @@ isCursor := src.ShowCursor
 	return
 }
 
+// changedModes devuelve los modos que la configuracion cambia respecto al estado actual del terminal
+// (ver TerminalState). Los valores por defecto (ver Default) no cambian nada: asi un renderizado
+// dentro de la pantalla alternativa de un visor no vuelve a la principal
+func (src *UI_Settings) changedModes() (modes []int) {
+	if !src.ShowCursor && terminalMode(ansi.ModeCursorVisible)		{ modes = append(modes, ansi.ModeCursorVisible) }
+	if src.AlternativeScreen && !terminalMode(ansi.ModeAltScreen)	{ modes = append(modes, ansi.ModeAltScreen) }
+	if !src.Auto_Wrap && terminalMode(ansi.ModeAutoWrap)			{ modes = append(modes, ansi.ModeAutoWrap) }
+
+	return modes
+}
+
 // Renderiza los bloques dentro de una imagen a un formato Unicode/ANSI y los guarda en un buffer
+// El estado (paridad de la fila inicial) es local a cada llamada, lo que permite
+// renderizar varias imagenes en paralelo sin compartir datos mutables
 func (src *RenderImage) renderBlocks(buf *bytes.Buffer) (err error) {
-	isYOdd = src.isYOdd()
-	for y := 0; y < src.Image.Rect.Dy(); y += PPB {
-		line := y * src.Image.Stride
+	isYOdd := src.isYOdd()
+	rows := src.cellRows(isYOdd)
 
-		for x := 0; x < src.Image.Stride; x += BPP {
-			err = src.renderBlock(buf, line + x)
+	workers := src.workerCount(rows)
+	if workers > 1 { return src.renderBands(buf, rows, workers, isYOdd) }
+
+	return src.renderRows(buf, 0, rows, isYOdd)
+}
+
+// renderRows renderiza las filas de celdas [from, to) de la imagen
+// Cada fila termina con endLine, que resetea los colores, por lo que el resultado
+// no depende de lo que se haya escrito antes de la fila 'from'
+func (src *RenderImage) renderRows(buf *bytes.Buffer, from, to int, isYOdd bool) (err error) {
+	for row := from; row < to; row++ {
+		line := rowStart(row, isYOdd) * src.Image.Stride
+
+		for x := 0; x < src.Image.Rect.Dx() * BPP; x += BPP {
+			err = src.renderBlock(buf, line + x, isYOdd)
 			if err != nil { return err }
 		}
 		err = src.endLine(buf)
 		if err != nil { return err }
-
-		if isYOdd && y == 0 { y-- }
 	}
 	
 	return
 }
 
+// cellRows cuenta las filas de celdas del terminal que ocupa la imagen
+// Si la fila inicial es impar, la primera celda contiene un unico pixel
+func (src *RenderImage) cellRows(isYOdd bool) int {
+	height := src.Image.Rect.Dy()
+	if height <= 0 { return 0 }
+	if isYOdd { return 1 + height / PPB }
+
+	return (height + 1) / PPB
+}
+
+// rowStart devuelve la fila de pixeles con la que empieza una fila de celdas
+func rowStart(row int, isYOdd bool) int {
+	if isYOdd && row > 0 { return row*PPB - 1 }
+
+	return row * PPB
+}
 
 // Renderiza un unico bloque Unicode
-func (src *RenderImage) renderBlock(buf *bytes.Buffer, index int) (err error) {
+func (src *RenderImage) renderBlock(buf *bytes.Buffer, index int, isYOdd bool) (err error) {
 	src.validateIndex(buf, index)
 
-	fgColor, bgColor := src.getPixels(index) // frente y fondo
+	fgColor, bgColor := src.getPixels(index, isYOdd) // frente y fondo
+
+	// Sin componer (ShadeTransparency) los colores se pintan sin el alfa premultiplicado
+	if src.ShadeTransparency { fgColor, bgColor = straightColor(fgColor), straightColor(bgColor) }
 	
-	block := src.determineBlockType(index, fgColor, bgColor)
-	if block == 0 { return }
+	block := src.determineBlockType(index, fgColor, bgColor, isYOdd)
+	if block == 0 {
+		// Celda transparente: se salta sin pintarla, dejando lo que hubiera debajo
+		buf.Write(moveRight)
+		return
+	}
 
 	if src.sameColor(buf, index, block, fgColor, bgColor) { return }
 
 	buf.Grow(39)
-	buf.Write(ansi.PaintRune(block, fgColor, bgColor, false))
+	code := buf.AvailableBuffer()
+	ansi.AppendPaintRune(&code, block, fgColor, bgColor, false)
+	buf.Write(code)
 	return
 }
 
@@ -236,7 +390,6 @@ func (src *RenderImage) renderBlock(buf *bytes.Buffer, index int) (err error) {
 func (src *RenderImage) sameColor(blockBuf *bytes.Buffer, index int, block rune, fgColor, bgColor color.RGBA) bool {
 	lowerIndex := index + src.Image.Stride
 	isX_0 := src.isX_0(index)
-	var byteBuf []byte 
 
 	if !isX_0 {
 		var sameUpper bool
@@ -245,14 +398,15 @@ func (src *RenderImage) sameColor(blockBuf *bytes.Buffer, index int, block rune,
 
 		// Verifica que el indice inferior este dentro del rango
 		// y que el indice superior no sea el primer pixel de la fila
-		if lowerIndex+3 < len(src.Image.Pix) && index-4 >= 0 {
+		if lowerIndex+3 < src.pixLen() && index-4 >= 0 {
 			sameUpper	= src.isSameColor(index, index - 4)
 			sameLower 	= src.isSameColor(lowerIndex, lowerIndex - 4)
 			sameBlock	= src.isSameColor(index, lowerIndex)
 		}
 
 		if sameUpper && sameLower {
-			if sameBlock {
+			// Un espacio solo sustituye a los medios bloques: los caracteres ░▒▓ se repiten
+			if sameBlock && !isShadeBlock(block) {
 				blockBuf.Grow(1)
 				blockBuf.WriteRune(' ')
 
@@ -265,6 +419,7 @@ func (src *RenderImage) sameColor(blockBuf *bytes.Buffer, index int, block rune,
 
 		} else if sameUpper {
 			blockBuf.Grow(22)
+			byteBuf := blockBuf.AvailableBuffer()
 			ansi.GetANSI_Color(&byteBuf, bgColor.R, bgColor.G, bgColor.B, false)
 			blockBuf.Write(byteBuf)
 			blockBuf.WriteRune(block)
@@ -273,6 +428,7 @@ func (src *RenderImage) sameColor(blockBuf *bytes.Buffer, index int, block rune,
 		
 		} else if sameLower {
 			blockBuf.Grow(22)
+			byteBuf := blockBuf.AvailableBuffer()
 			ansi.GetANSI_Color(&byteBuf, fgColor.R, fgColor.G, fgColor.B, true)
 			blockBuf.Write(byteBuf)
 			blockBuf.WriteRune(block)
@@ -284,6 +440,11 @@ func (src *RenderImage) sameColor(blockBuf *bytes.Buffer, index int, block rune,
 	return false
 }
 
+// isShadeBlock indica si el bloque es uno de los caracteres de transparencia (ShadeTransparency)
+func isShadeBlock(block rune) bool {
+	return block != upperBlock && block != lowerBlock
+}
+
 // isX_0 verifica si el indice es el primer pixel de la fila
 func (src *RenderImage)  isX_0(index int) bool {
 	return index % src.Image.Stride == 0
@@ -294,8 +455,8 @@ func (src *RenderImage)  isX_0(index int) bool {
 func (src *RenderImage) isSameColor(indexColor1, indexColor2 int) bool {
 	 if indexColor1 < 0 ||
 	 	indexColor2 < 0 ||
-        indexColor1+3 >= len(src.Image.Pix) ||
-		indexColor2+3 >= len(src.Image.Pix) {
+        indexColor1+3 >= src.pixLen() ||
+		indexColor2+3 >= src.pixLen() {
 
         return false
     }
@@ -309,13 +470,15 @@ func (src *RenderImage) isSameColor(indexColor1, indexColor2 int) bool {
 
 // validateIndex Valida que el indice este dentro del rango
 func (src *RenderImage) validateIndex(buf *bytes.Buffer, index int) {
-	if index < 0 || index+3 >= len(src.Image.Pix) {
-        buf.Write(ansi.PaintRune(' ', color.RGBA{}, color.RGBA{}, false))
+	if index < 0 || index+3 >= src.pixLen() {
+        code := buf.AvailableBuffer()
+        ansi.AppendPaintRune(&code, ' ', color.RGBA{}, color.RGBA{}, false)
+        buf.Write(code)
     }
 }
 
 // determineBlockType Determina el tipo de bloque a usar
-func (src *RenderImage) determineBlockType(index int, foreground, background color.RGBA) rune {
+func (src *RenderImage) determineBlockType(index int, foreground, background color.RGBA, isYOdd bool) rune {
     // Caso 1: Transparencia en ambos píxeles
     if foreground.A < ALPHA_4 && background.A < ALPHA_4 {
 		if foreground.A < ALPHA_1 && background.A < ALPHA_1 { return 0 }
@@ -345,12 +508,12 @@ func (src *RenderImage) isYOdd() bool {
 // getPixels Obtiene el color del pixel superior e inferior, segun el indice del pixel superior 
 // y retorna el color del pixel superior y el color del pixel inferior
 // si el pixel inferior no existe, retorna un color transparente
-func (src *RenderImage) getPixels(upperIndex int) (foreground, background color.RGBA) {
+func (src *RenderImage) getPixels(upperIndex int, isYOdd bool) (foreground, background color.RGBA) {
     foreground = src.getPixel(upperIndex)
 	background = color.RGBA{}
 	
     lowerIndex := upperIndex + src.Image.Stride
-    if lowerIndex+3 >= len(src.Image.Pix) {return } 					// No hay píxel de fondo
+    if lowerIndex+3 >= src.pixLen() {return } 					// No hay píxel de fondo
 
 	if isFirstRow(upperIndex, src.Image.Stride) && isYOdd { return }
     background = src.getPixel(lowerIndex)
@@ -358,6 +521,15 @@ func (src *RenderImage) getPixels(upperIndex int) (foreground, background color.
     return foreground, background
 }
 
+// pixLen devuelve la cantidad de bytes de Pix que pertenecen a la imagen
+// En una subimagen (por ejemplo un recorte) Pix sigue hasta el final de la imagen
+// original, por lo que los limites se calculan con Rect y no con len(Pix)
+func (src *RenderImage) pixLen() int {
+	if src.Image.Rect.Empty() { return 0 }
+
+	return (src.Image.Rect.Dy() - 1) * src.Image.Stride + src.Image.Rect.Dx() * BPP
+}
+
 // getPixel Obtiene el color del pixel, segun el indice (RGBA)
 func (src *RenderImage) getPixel(index int) (pixel color.RGBA) {
 	return color.RGBA{
@@ -374,6 +546,8 @@ func (src *RenderImage) endLine(buf *bytes.Buffer) (err error) {
 	_,err = buf.Write(resetColor)
 	if err != nil { return err }
 
+	if src.opts.Inline { return src.inlineLine(buf) }
+
 	_,err = buf.Write(moveDown)
 	if err != nil { return err }
 	
@@ -383,6 +557,39 @@ func (src *RenderImage) endLine(buf *bytes.Buffer) (err error) {
 	return
 }
 
+// inlineLine pasa a la fila siguiente en modo Inline: un salto de linea (que desplaza la
+// pantalla si hace falta) y la columna inicial relativa al margen izquierdo
+func (src *RenderImage) inlineLine(buf *bytes.Buffer) (err error) {
+	_,err = buf.WriteString("\r\n")
+	if err != nil { return err }
+
+	if src.InitialPoint.X == 0 { return }
+
+	_,err = buf.WriteString(ansi.MoveRight(src.InitialPoint.X))
+	return err
+}
+
+// reserveLines prepara el modo Inline: baja tantas filas como ocupa la imagen (desplazando
+// la pantalla si el cursor esta abajo) y vuelve a subirlas, para que la imagen quepa entera
+// debajo del cursor. Sin terminal, o si la imagen no cabe, los saltos de cada fila bastan
+func (src *RenderImage) reserveLines(buf *bytes.Buffer) (err error) {
+	rows := src.cellRows(src.isYOdd())
+
+	size, sizeErr := GetTerminalSize()
+	if sizeErr == nil && rows > 0 && rows < size.Y {
+		_,err = buf.WriteString(strings.Repeat("\n", rows) + ansi.MoveUp(rows))
+		if err != nil { return err }
+	}
+
+	_,err = buf.WriteString("\r")
+	if err != nil { return err }
+
+	if src.InitialPoint.X == 0 { return }
+
+	_,err = buf.WriteString(ansi.MoveRight(src.InitialPoint.X))
+	return err
+}
+
 // initializeRender Coloca el cursor en la posision inicial para imprimir la imagen
 func (src *RenderImage) initializeRender(buf *bytes.Buffer) (err error) {
 	startCol, startRow := src.calculateStartPosition()
@@ -390,19 +597,24 @@ func (src *RenderImage) initializeRender(buf *bytes.Buffer) (err error) {
 	err = src.opts.validateUI_Settings(buf, false); 
 	if err != nil {	return err }
 
+	if src.opts.Inline { return src.reserveLines(buf) }
+
 	_, err = buf.WriteString(ansi.MoveTo(startCol, startRow)); 
 	if err != nil {	return err }
 
 	return
 }
 
-// finalizeRender Finaliza el renderizando de imagen, volviendo a los valores default
+// finalizeRender Finaliza el renderizando de imagen, devolviendo los modos a su estado anterior
 func (src *RenderImage) finalizeRender(buf *bytes.Buffer) (err error) {
 	finalCol, finalRow := src.calculateFinalPosition()
 
 	err = src.opts.validateUI_Settings(buf, true)
 	if err != nil { return err }
 
+	// En modo Inline el ultimo endLine ya dejo el cursor en la fila siguiente a la imagen
+	if src.opts.Inline { return buf.WriteByte('\r') }
+
 	err = src.finalPosition(buf, finalCol, finalRow)
 	if err != nil { return err }
 	return 
@@ -433,7 +645,7 @@ func (src *RenderImage) finalPosition(buf *bytes.Buffer, finalCol, finalRow int)
 	_, err = buf.WriteString(ansi.MoveTo(finalCol, finalRow))
 	if err != nil { return err }
 	
-	if src.Image.Rect.Max.X >= terminalSize.X {
+	if src.Image.Rect.Dx() >= cachedPixelSize().X {
 		_, err = buf.Write(moveDown)
 		if err != nil { return err }	
 	}
@@ -442,103 +654,28 @@ func (src *RenderImage) finalPosition(buf *bytes.Buffer, finalCol, finalRow int)
 }
 
 
-// Precalculo del tamaño del terminal para pixeles
+// Ultimo tamaño conocido del terminal para pixeles
 // cuenta los pixeles que se pueden usar dentro de un bloque unicode
 // bloque superior ('▀') y bloque inferior ('▄')
-var terminalSize image.Point
+// Se actualiza con cada consulta correcta (GetTerminalPixelSize) y con WatchResize;
+// se guarda de forma atomica porque lo leen varias goroutines
+var terminalSize atomic.Pointer[image.Point]
+
+// cachedPixelSize devuelve el ultimo tamaño conocido del terminal para pixeles
+// (DefaultTerminalSize si nunca se pudo consultar)
+func cachedPixelSize() image.Point {
+	if size := terminalSize.Load(); size != nil { return *size }
+	return image.Pt(DefaultTerminalSize.X, DefaultTerminalSize.Y * PPB)
+}
 
 
 // Obtiene el tamaño en terminal para pixeles
 // cuenta los pixeles que se pueden usar dentro de un bloque unicode 
 // bloque superior ('▀') y bloque inferior ('▄')
-func GetTerminalPixelSize() (terminalSize image.Point, err error) {
+func GetTerminalPixelSize() (pixelSize image.Point, err error) {
 	size, err := GetTerminalSize()
 
-	terminalSize = image.Pt(size.X, size.Y*2)
-	return terminalSize, err
-}
-
-
-// Retorna el punto maximo del tamaño actual del terminal
-// La cantidad de caracteres de ancho y largo que se pueden utilizar
-func GetTerminalSize() (size image.Point, err error) {
-	var info windows.ConsoleScreenBufferInfo
-	handle := windows.Handle(windows.Stdout)
-	err = windows.GetConsoleScreenBufferInfo(handle, &info)
-	if err != nil {return image.Point{}, err}
-	
-	ancho := int(info.Window.Right - info.Window.Left + 1)
-	alto := int(info.Window.Bottom - info.Window.Top + 1)
-
-
-	size = image.Pt(ancho, alto)
-	return size, nil
-}
-
-// Obtiene una imagen de tipo RGBA reusable
-// Es recomendable que al finalizar su uso se devuelva al pool 
-// usando PutReusableRGBA para evitar fugas de memoria.
-func GetReusableRGBA(bounds image.Rectangle) *image.RGBA {
-	pool := getRGBA_Pool(bounds)
-	img := pool.Get().(*image.RGBA)
-	img.Rect = bounds
-
-	return img
-}
-
-// Resoluciones predefinidas para las imágenes reutilizables
-// Estas resoluciones son utilizadas para optimizar el uso de memoria y rendimiento
-// al reutilizar imágenes de diferentes tamaños según las necesidades del terminal.
-var resolutions = []image.Point{
-	{141, 58},			// valor por default del terminal
-	{160, 120},
-	{320, 240},
-	{640, 480},
-	{1280, 720},
-	{1920, 1080},
-	{2560, 1440},
-	{3840, 2160},
+	pixelSize = image.Pt(size.X, size.Y*2)
+	if err == nil { terminalSize.Store(&pixelSize) }
+	return pixelSize, err
 }
-
-// rgbaPools almacena pools de imágenes RGBA reutilizables
-var rgbaPools = make(map[image.Point]*sync.Pool)
-
-// asignRGBA_Pools inicializa los pools de imágenes RGBA reutilizables
-func asignRGBA_Pools() {
-	for _, resolution := range resolutions {
-		rgbaPools[resolution] = &sync.Pool{
-			New: func() any { return image.NewRGBA(image.Rect(0,0, resolution.X, resolution.Y)) },
-		}
-	}
-}
-
-// getRGBA_Pool obtiene el pool de imágenes RGBA reutilizables adecuado
-// según el tamaño de los bounds proporcionados.
-// Si no hay un pool adecuado, devuelve un nuevo pool con el tamaño máximo.
-// Esto permite reutilizar imágenes de diferentes tamaños sin necesidad de crear nuevas instancias.
-func getRGBA_Pool(bounds image.Rectangle) *sync.Pool {
-	max := bounds.Max
-	for _, resolution := range resolutions {
-		if resolution.X >= max.X && resolution.Y >= max.Y {
-			return rgbaPools[resolution] 
-		}
-	}
-
-	return &sync.Pool{
-		New: func() any { return image.NewRGBA(image.Rect(0,0, max.X, max.Y)) },
-	}
-}
-
-// PutReusableRGBA limpia y devuelve una imagen RGBA al pool de reutilización.
-// Esto permite que la imagen sea reutilizada en futuras operaciones,
-func PutReusableRGBA(img *image.RGBA) {
-	if img == nil { return }
-
-	pixels := img.Pix
-	for i := range pixels {
-		pixels[i] = 0
-	}
-
-	pool, ok := rgbaPools[img.Rect.Max]
-	if ok { pool.Put(img) }
-}
\ No newline at end of file
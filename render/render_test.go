@@ -0,0 +1,133 @@
+package terminal
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"sync"
+	"testing"
+
+	"golang.org/x/image/draw"
+)
+
+// testImage crea una imagen opaca con un degradado que depende de 'seed',
+// para que cada imagen de prueba produzca una salida distinta
+func testImage(width, height, seed int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0,0, width, height))
+	for y := range height {
+		for x := range width {
+			img.SetRGBA(x, y, color.RGBA{
+				R: uint8(x * 7 + seed * 31),
+				G: uint8(y * 5 + seed * 17),
+				B: uint8((x + y) * 3 + seed),
+				A: 255,
+			})
+		}
+	}
+	return img
+}
+
+// renderCase es una imagen y configuracion a renderizar en las pruebas de concurrencia
+type renderCase struct {
+	name	string
+	src		RenderImage
+}
+
+// renderCases devuelve imagenes de distintos tamaños con distintas configuraciones:
+// escalado, recorte, giro, posicion impar, bandas en paralelo y modos UI
+func renderCases() []renderCase {
+	hidden := UI_Settings{ShowCursor: false, Auto_Wrap: false}
+
+	var cases []renderCase
+	add := func(name string, src *RenderImage, change func(*RenderImage)) {
+		if change != nil { change(src) }
+		cases = append(cases, renderCase{name: name, src: *src})
+	}
+
+	add("small", NewImage(testImage(40, 30, 1)), nil)
+	add("odd-point", NewImage(testImage(33, 21, 2)), func(src *RenderImage) { src.InitialPoint = image.Pt(3, 5) })
+	add("scaled", NewCustomImage(testImage(300, 200, 3), image.Rect(0,0, 90, 60), image.Point{}, draw.BiLinear, &hidden), nil)
+	add("catmull", NewCustomImage(testImage(257, 191, 4), image.Rect(0,0, 77, 51), image.Pt(1, 1), draw.CatmullRom, UI_Settings{}.Default()), nil)
+	add("crop", NewImage(testImage(120, 80, 5)), func(src *RenderImage) { src.Crop = image.Rect(10, 7, 70, 49) })
+	add("rotated", NewImage(testImage(64, 48, 6)), func(src *RenderImage) { src.Transform.Rotation = 1; src.Transform.FlipH = true })
+	add("bands", NewImage(testImage(140, 110, 7)), func(src *RenderImage) { src.Workers = 4 })
+	add("zoom", NewImage(testImage(200, 150, 8)), func(src *RenderImage) { src.Zoom = 1.5; src.Viewport = image.Pt(20, 11) })
+
+	return cases
+}
+
+// TestConcurrentRender renderiza varias imagenes a la vez desde muchas goroutines
+// (con GetPNG y WriteTo) y compara cada salida con la del renderizado secuencial
+// Se debe ejecutar con 'go test -race'
+func TestConcurrentRender(t *testing.T) {
+	cases := renderCases()
+
+	want := make([][]byte, len(cases))
+	for i, c := range cases {
+		src := c.src
+		out, _, err := src.GetPNG()
+		if err != nil { t.Fatalf("%s: %v", c.name, err) }
+		want[i] = out
+	}
+
+	const goroutines = 8
+	const rounds = 4
+
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines * rounds * len(cases))
+	for g := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for round := range rounds {
+				for i := range cases {
+					// Cada goroutine recorre los casos en distinto orden
+					index := (i + g + round) % len(cases)
+					c := cases[index]
+					src := c.src
+
+					var got []byte
+					if (g + round) % 2 == 0 {
+						out, _, err := src.GetPNG()
+						if err != nil { errs <- fmt.Errorf("%s: GetPNG: %v", c.name, err); continue }
+						got = out
+					} else {
+						var buf bytes.Buffer
+						_, err := src.WriteTo(&buf)
+						if err != nil { errs <- fmt.Errorf("%s: WriteTo: %v", c.name, err); continue }
+						got = buf.Bytes()
+					}
+
+					if !bytes.Equal(got, want[index]) {
+						errs <- fmt.Errorf("%s: la salida concurrente difiere de la secuencial (%d bytes, se esperaban %d)",
+							c.name, len(got), len(want[index]))
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs { t.Error(err) }
+}
+
+// TestGetPNG_Owned comprueba que los bytes de GetPNG pertenecen al llamador:
+// renderizar otras imagenes despues no los modifica
+func TestGetPNG_Owned(t *testing.T) {
+	cases := renderCases()
+
+	src := cases[0].src
+	out, _, err := src.GetPNG()
+	if err != nil { t.Fatal(err) }
+	saved := bytes.Clone(out)
+
+	for _, c := range cases[1:] {
+		src := c.src
+		_, _, err := src.GetPNG()
+		if err != nil { t.Fatalf("%s: %v", c.name, err) }
+	}
+
+	if !bytes.Equal(out, saved) { t.Error("GetPNG devolvio bytes que otro renderizado modifico") }
+}
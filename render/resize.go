@@ -0,0 +1,67 @@
+package terminal
+
+import (
+	"image"
+	"sync"
+	"time"
+)
+
+// ResizeDebounce es el tiempo sin nuevos cambios de tamaño que se espera antes de avisar,
+// para no redibujar en cada paso mientras se arrastra el borde de la ventana
+var ResizeDebounce = 50 * time.Millisecond
+
+// ResizeWatcher vigila el tamaño del terminal (SIGWINCH en Unix, WINDOW_BUFFER_SIZE_EVENT
+// en Windows) y actualiza el tamaño guardado en el paquete antes de avisar por C
+// En Windows esos eventos llegan por la cola de entrada de la consola, asi que solo se
+// reciben mientras un EventLoop lee la entrada (Displacement, Gallery, Grid...)
+type ResizeWatcher struct {
+	// Nuevo tamaño del terminal en columnas y filas
+	// Solo se conserva el ultimo aviso sin leer
+	C		<-chan image.Point
+
+	done	chan struct{}
+	once	sync.Once
+}
+
+// WatchResize empieza a vigilar el tamaño del terminal
+// Los cambios seguidos separados por menos de 'debounce' se avisan una sola vez
+func WatchResize(debounce time.Duration) *ResizeWatcher {
+	sizes := make(chan image.Point, 1)
+	watcher := &ResizeWatcher{C: sizes, done: make(chan struct{})}
+
+	signals, stop := resizeSignals()
+
+	go func() {
+		defer stop()
+
+		timer := time.NewTimer(debounce)
+		timer.Stop()
+
+		for {
+			select {
+			case <-watcher.done:
+				timer.Stop()
+				return
+			case <-signals:
+				timer.Reset(debounce)
+			case <-timer.C:
+				pixelSize, err := GetTerminalPixelSize()
+				if err != nil { continue }
+
+				// Descarta el aviso anterior si nadie lo leyo
+				select {
+				case <-sizes:
+				default:
+				}
+				sizes <- image.Pt(pixelSize.X, pixelSize.Y / PPB)
+			}
+		}
+	}()
+
+	return watcher
+}
+
+// Stop deja de vigilar el tamaño del terminal
+func (w *ResizeWatcher) Stop() {
+	w.once.Do(func() { close(w.done) })
+}
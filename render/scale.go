@@ -0,0 +1,342 @@
+package terminal
+
+import (
+	"image"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+)
+
+// Parametros del escalado por bandas
+const (
+	// minParallelPixels es la cantidad minima de pixeles (origen o destino) para que
+	// el modo automatico reparta el escalado entre varias goroutines
+	minParallelPixels = 256 * 1024
+
+	// minTileRows es la altura minima, en filas de destino, de una banda de escalado
+	minTileRows = 16
+)
+
+// scaleWorkers determina cuantas goroutines usar para escalar sr sobre dr
+func (src *RenderImage) scaleWorkers(dr, sr image.Rectangle) int {
+	workers := src.Workers
+	if workers == 0 {
+		if max(GetAreaRect(dr), GetAreaRect(sr)) < minParallelPixels { return 1 }
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	return Clamp(workers, 1, max(dr.Dy() / minTileRows, 1))
+}
+
+// scaleRGBA escala la region sr de src sobre la region dr de dst dividiendo el
+// destino en bandas horizontales que se procesan en paralelo.
+//
+// dst debe llegar a cero (como las imagenes de GetReusableRGBA): el resultado es el
+// mismo que el de interpolator.Scale(dst, dr, src, sr, draw.Over, nil) sobre una
+// imagen vacia. Los interpoladores pixel a pixel (NearestNeighbor, ApproxBiLinear,
+// boxFilter) se aplican banda por banda sobre subimagenes de dst; los kernels
+// (BiLinear, CatmullRom) usan kernelScaler, que solo visita las filas de origen
+// que cubre cada banda mas el solapamiento del kernel.
+func scaleRGBA(dst *image.RGBA, dr image.Rectangle, src *image.RGBA, sr image.Rectangle, interpolator draw.Interpolator, workers int) {
+	adr := dst.Bounds().Intersect(dr)
+	if adr.Empty() || sr.Empty() { return }
+
+	kernel, isKernel := interpolator.(*draw.Kernel)
+	if isKernel && !sr.In(src.Bounds()) { isKernel = false }
+
+	var scaler *kernelScaler
+	if isKernel { scaler = newKernelScaler(kernel, dr, sr) }
+
+	scaleTile := func(tile image.Rectangle) {
+		if isKernel {
+			scaler.scale(dst, dr, src, sr, tile)
+			return
+		}
+		interpolator.Scale(dst.SubImage(tile).(*image.RGBA), dr, src, sr, draw.Over, nil)
+	}
+
+	if workers <= 1 {
+		scaleTile(adr)
+		return
+	}
+
+	tileRows := max((adr.Dy() + workers*bandsPerWorker - 1) / (workers*bandsPerWorker), minTileRows)
+	tiles := (adr.Dy() + tileRows - 1) / tileRows
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range min(workers, tiles) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				tile := int(next.Add(1) - 1)
+				if tile >= tiles { return }
+
+				minY := adr.Min.Y + tile*tileRows
+				scaleTile(image.Rect(adr.Min.X, minY, adr.Max.X, min(minY + tileRows, adr.Max.Y)))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// kernelScaler es un escalador separable equivalente al de x/image/draw para
+// *draw.Kernel, pero capaz de procesar solo un rectangulo del destino.
+// Reproduce los mismos pesos y el mismo orden de las operaciones en coma flotante,
+// por lo que el resultado es identico byte a byte al de kernel.Scale.
+type kernelScaler struct {
+	horizontal, vertical	kernelDistrib
+}
+
+// kernelSource es el rango de contribuciones de una columna o fila de destino
+// y la inversa de su peso total
+type kernelSource struct {
+	i, j				int32
+	invTotalWeight		float64
+	invTotalWeightFFFF	float64
+}
+
+// kernelContrib es el peso de una columna o fila de origen
+type kernelContrib struct {
+	coord	int32
+	weight	float64
+}
+
+// kernelDistrib reparte las columnas (o filas) de origen sobre las de destino
+type kernelDistrib struct {
+	sources		[]kernelSource
+	contribs	[]kernelContrib
+}
+
+// newKernelScaler precalcula los pesos horizontales y verticales para escalar sr sobre dr
+func newKernelScaler(kernel *draw.Kernel, dr, sr image.Rectangle) *kernelScaler {
+	return &kernelScaler{
+		horizontal: newKernelDistrib(kernel, int32(dr.Dx()), int32(sr.Dx())),
+		vertical:   newKernelDistrib(kernel, int32(dr.Dy()), int32(sr.Dy())),
+	}
+}
+
+// newKernelDistrib distribuye sw columnas (o filas) de origen sobre dw de destino
+func newKernelDistrib(kernel *draw.Kernel, dw, sw int32) kernelDistrib {
+	scale := float64(sw) / float64(dw)
+	halfWidth, kernelArgScale := kernel.Support, 1.0
+
+	// Al reducir se ensancha el soporte del kernel para visitar todos los pixeles de origen
+	if scale > 1 {
+		halfWidth *= scale
+		kernelArgScale = 1 / scale
+	}
+
+	n, sources := int32(0), make([]kernelSource, dw)
+	centers := make([]float64, dw)
+	for x := range sources {
+		center := float64((float64(x)+0.5)*scale) - 0.5
+		i := int32(math.Floor(center - halfWidth))
+		if i < 0 { i = 0 }
+
+		j := int32(math.Ceil(center + halfWidth))
+		if j > sw {
+			j = sw
+			if j < i { j = i }
+		}
+		sources[x] = kernelSource{i: i, j: j}
+		centers[x] = center
+		n += j - i
+	}
+
+	contribs := make([]kernelContrib, 0, n)
+	for k, b := range sources {
+		totalWeight := 0.0
+		l := int32(len(contribs))
+		for coord := b.i; coord < b.j; coord++ {
+			t := math.Abs((centers[k] - float64(coord)) * kernelArgScale)
+			if t >= kernel.Support { continue }
+
+			weight := kernel.At(t)
+			if weight == 0 { continue }
+
+			totalWeight += weight
+			contribs = append(contribs, kernelContrib{coord, weight})
+		}
+		totalWeight = 1 / totalWeight
+		sources[k] = kernelSource{
+			i:                  l,
+			j:                  int32(len(contribs)),
+			invTotalWeight:     totalWeight,
+			invTotalWeightFFFF: totalWeight / 0xffff,
+		}
+	}
+
+	return kernelDistrib{sources, contribs}
+}
+
+// span devuelve el rango [from, to) de coordenadas de origen que necesitan
+// las coordenadas de destino [lo, hi); incluye el solapamiento del kernel
+func (d *kernelDistrib) span(lo, hi int) (from, to int32) {
+	from, to = math.MaxInt32, 0
+	for _, s := range d.sources[lo:hi] {
+		if s.i == s.j { continue }
+
+		from = min(from, d.contribs[s.i].coord)
+		to = max(to, d.contribs[s.j-1].coord + 1)
+	}
+	if from > to { from = to }
+
+	return from, to
+}
+
+// scale escala sobre dst unicamente el rectangulo 'tile' (en coordenadas de dst).
+// Primero reduce en horizontal las filas de origen necesarias a un buffer temporal
+// propio de la banda y luego reduce ese buffer en vertical sobre el destino.
+func (z *kernelScaler) scale(dst *image.RGBA, dr image.Rectangle, src *image.RGBA, sr image.Rectangle, tile image.Rectangle) {
+	adr := tile.Sub(dr.Min)
+	y0, y1 := z.vertical.span(adr.Min.Y, adr.Max.Y)
+	tw := adr.Dx()
+
+	tmp := make([][4]float64, tw * int(y1 - y0))
+
+	// Pasada horizontal: columnas de origen -> columnas de destino
+	t := 0
+	for y := y0; y < y1; y++ {
+		row := (sr.Min.Y + int(y) - src.Rect.Min.Y) * src.Stride
+		for _, s := range z.horizontal.sources[adr.Min.X:adr.Max.X] {
+			var pr, pg, pb, pa float64
+			for _, c := range z.horizontal.contribs[s.i:s.j] {
+				pi := row + (sr.Min.X + int(c.coord) - src.Rect.Min.X) * BPP
+				pr += float64(float64(uint32(src.Pix[pi+0]) * 0x101) * c.weight)
+				pg += float64(float64(uint32(src.Pix[pi+1]) * 0x101) * c.weight)
+				pb += float64(float64(uint32(src.Pix[pi+2]) * 0x101) * c.weight)
+				pa += float64(float64(uint32(src.Pix[pi+3]) * 0x101) * c.weight)
+			}
+			tmp[t] = [4]float64{
+				pr * s.invTotalWeightFFFF,
+				pg * s.invTotalWeightFFFF,
+				pb * s.invTotalWeightFFFF,
+				pa * s.invTotalWeightFFFF,
+			}
+			t++
+		}
+	}
+
+	// Pasada vertical: filas del buffer temporal -> filas de destino
+	for x := range tw {
+		d := (tile.Min.Y - dst.Rect.Min.Y) * dst.Stride + (tile.Min.X + x - dst.Rect.Min.X) * BPP
+		for _, s := range z.vertical.sources[adr.Min.Y:adr.Max.Y] {
+			var pr, pg, pb, pa float64
+			for _, c := range z.vertical.contribs[s.i:s.j] {
+				p := &tmp[int(c.coord - y0) * tw + x]
+				pr += float64(p[0] * c.weight)
+				pg += float64(p[1] * c.weight)
+				pb += float64(p[2] * c.weight)
+				pa += float64(p[3] * c.weight)
+			}
+
+			pr, pg, pb = min(pr, pa), min(pg, pa), min(pb, pa)
+
+			dst.Pix[d+0] = uint8(ftou(pr * s.invTotalWeight) >> 8)
+			dst.Pix[d+1] = uint8(ftou(pg * s.invTotalWeight) >> 8)
+			dst.Pix[d+2] = uint8(ftou(pb * s.invTotalWeight) >> 8)
+			dst.Pix[d+3] = uint8(ftou(pa * s.invTotalWeight) >> 8)
+			d += dst.Stride
+		}
+	}
+}
+
+// ftou convierte el rango [0.0, 1.0] a [0, 0xffff]
+func ftou(f float64) uint16 {
+	i := int32(float64(0xffff*f) + 0.5)
+	if i > 0xffff { return 0xffff }
+	if i > 0 { return uint16(i) }
+
+	return 0
+}
+
+// boxFilter es el atajo de los kernels (BiLinear, CatmullRom) para las reducciones enteras exactas
+// (ver scaleInterpolator): cada pixel de destino es el promedio de su bloque de origen
+type boxFilter struct {
+	kx, ky	int
+}
+
+// Scale implementa draw.Scaler sobre la parte de dr que cae dentro de dst (una banda de scaleRGBA)
+// Solo se usa con imagenes RGBA y sr dentro de src; en otro caso delega en draw.BiLinear
+func (box boxFilter) Scale(dst draw.Image, dr image.Rectangle, src image.Image, sr image.Rectangle, op draw.Op, opts *draw.Options) {
+	dstRGBA, okDst := dst.(*image.RGBA)
+	srcRGBA, okSrc := src.(*image.RGBA)
+
+	if !okDst || !okSrc || opts != nil || !sr.In(src.Bounds()) {
+		draw.BiLinear.Scale(dst, dr, src, sr, op, opts)
+		return
+	}
+
+	boxScale(dstRGBA, dr, srcRGBA, sr, box.kx, box.ky, op)
+}
+
+// Transform implementa draw.Transformer delegando en draw.BiLinear
+func (boxFilter) Transform(dst draw.Image, m f64.Aff3, src image.Image, sr image.Rectangle, op draw.Op, opts *draw.Options) {
+	draw.BiLinear.Transform(dst, m, src, sr, op, opts)
+}
+
+// scaleInterpolator devuelve el interpolador con el que se escala sr sobre dr: el de la imagen,
+// salvo que sea un kernel y la reduccion sea entera y exacta en ambos ejes (por ejemplo 4000x3000
+// sobre 1000x750), donde el promedio por area es mucho mas rapido y no pierde ningun pixel de origen
+// NearestNeighbor y ApproxBiLinear se respetan siempre, ya que se eligen por su aspecto pixelado
+func (src *RenderImage) scaleInterpolator(dr, sr image.Rectangle) draw.Interpolator {
+	_, isKernel := src.Interpolator.(*draw.Kernel)
+	if !isKernel { return src.Interpolator }
+
+	kx, ky, ok := integerRatio(dr, sr)
+	if !ok { return src.Interpolator }
+
+	return boxFilter{kx: kx, ky: ky}
+}
+
+// integerRatio devuelve los factores de reduccion si sr es un multiplo entero exacto de dr
+func integerRatio(dr, sr image.Rectangle) (kx, ky int, ok bool) {
+	if dr.Empty() || sr.Empty() { return 0, 0, false }
+	if sr.Dx() % dr.Dx() != 0 || sr.Dy() % dr.Dy() != 0 { return 0, 0, false }
+
+	return sr.Dx() / dr.Dx(), sr.Dy() / dr.Dy(), true
+}
+
+// boxScale reduce sr sobre dr promediando bloques de kx por ky pixeles de origen.
+// Solo escribe la parte de dr que cae dentro de dst, lo que permite usarlo por bandas
+func boxScale(dst *image.RGBA, dr image.Rectangle, src *image.RGBA, sr image.Rectangle, kx, ky int, op draw.Op) {
+	adr := dst.Bounds().Intersect(dr)
+	area := uint32(kx * ky)
+
+	for y := adr.Min.Y; y < adr.Max.Y; y++ {
+		d := dst.PixOffset(adr.Min.X, y)
+		sy := sr.Min.Y + (y - dr.Min.Y) * ky
+
+		for x := adr.Min.X; x < adr.Max.X; x++ {
+			sx := sr.Min.X + (x - dr.Min.X) * kx
+
+			var sum [BPP]uint32
+			for by := range ky {
+				s := src.PixOffset(sx, sy + by)
+				for range kx {
+					sum[0] += uint32(src.Pix[s+0])
+					sum[1] += uint32(src.Pix[s+1])
+					sum[2] += uint32(src.Pix[s+2])
+					sum[3] += uint32(src.Pix[s+3])
+					s += BPP
+				}
+			}
+
+			alpha := (sum[3] + area/2) / area
+			for c := range BPP {
+				value := (sum[c] + area/2) / area
+				if op == draw.Over {
+					value += uint32(dst.Pix[d+c]) * (255 - alpha) / 255
+				}
+				dst.Pix[d+c] = uint8(min(value, 255))
+			}
+			d += BPP
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package terminal
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+
+	"golang.org/x/image/draw"
+)
+
+// alphaImage crea una imagen con alfa variable (colores premultiplicados validos)
+func alphaImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0,0, width, height))
+	for y := range height {
+		for x := range width {
+			a := uint8(255 - (x * 13 + y * 7) % 200)
+			img.SetRGBA(x, y, color.RGBA{
+				R: uint8(uint32(x * 37 % 256) * uint32(a) / 255),
+				G: uint8(uint32(y * 53 % 256) * uint32(a) / 255),
+				B: uint8(uint32((x ^ y) % 256) * uint32(a) / 255),
+				A: a,
+			})
+		}
+	}
+	return img
+}
+
+// scaleCase es un escalado de sr (en la imagen de origen) sobre dr (en un destino de tamaño dst)
+type scaleCase struct {
+	src		image.Point
+	sr		image.Rectangle
+	dst		image.Point
+	dr		image.Rectangle
+}
+
+// scaleCases cubre reducciones y ampliaciones no enteras, tamaños impares (bandas incompletas
+// al final), recortes del origen y destinos desplazados como los de ScaleViewport
+func scaleCases() []scaleCase {
+	return []scaleCase{
+		{src: image.Pt(301, 207), sr: image.Rect(0,0, 301, 207), dst: image.Pt(97, 67), dr: image.Rect(0,0, 97, 67)},
+		{src: image.Pt(640, 480), sr: image.Rect(0,0, 640, 480), dst: image.Pt(213, 161), dr: image.Rect(0,0, 213, 161)},
+		{src: image.Pt(53, 41), sr: image.Rect(0,0, 53, 41), dst: image.Pt(171, 133), dr: image.Rect(0,0, 171, 133)},
+		{src: image.Pt(257, 199), sr: image.Rect(13, 9, 240, 187), dst: image.Pt(101, 77), dr: image.Rect(0,0, 101, 77)},
+		{src: image.Pt(120, 90), sr: image.Rect(0,0, 120, 90), dst: image.Pt(83, 59), dr: image.Rect(-40, -31, 251, 190)},
+	}
+}
+
+// TestScaleRGBA_Kernels compara scaleRGBA con BiLinear y CatmullRom, secuencial y por bandas,
+// con el escalado de x/image/draw sobre una imagen vacia: deben ser identicos byte a byte
+func TestScaleRGBA_Kernels(t *testing.T) {
+	interpolators := map[string]draw.Interpolator{
+		"BiLinear":			draw.BiLinear,
+		"CatmullRom":		draw.CatmullRom,
+		"NearestNeighbor":	draw.NearestNeighbor,
+		"ApproxBiLinear":	draw.ApproxBiLinear,
+	}
+
+	for _, c := range scaleCases() {
+		src := alphaImage(c.src.X, c.src.Y)
+
+		for name, interpolator := range interpolators {
+			want := image.NewRGBA(image.Rect(0,0, c.dst.X, c.dst.Y))
+			interpolator.Scale(want, c.dr, src, c.sr, draw.Over, nil)
+
+			for _, workers := range []int{1, 2, 3, 7} {
+				got := image.NewRGBA(image.Rect(0,0, c.dst.X, c.dst.Y))
+				scaleRGBA(got, c.dr, src, c.sr, interpolator, workers)
+
+				if !bytes.Equal(got.Pix, want.Pix) {
+					t.Errorf("%s, %v -> %v (dr %v), workers=%d: %s",
+						name, c.sr, c.dst, c.dr, workers, firstDiff(got, want))
+				}
+			}
+		}
+	}
+}
+
+// firstDiff describe el primer pixel en que difieren dos imagenes del mismo tamaño
+func firstDiff(got, want *image.RGBA) string {
+	for y := want.Rect.Min.Y; y < want.Rect.Max.Y; y++ {
+		for x := want.Rect.Min.X; x < want.Rect.Max.X; x++ {
+			if got.RGBAAt(x, y) != want.RGBAAt(x, y) {
+				return fmt.Sprintf("pixel (%d,%d) = %v, se esperaba %v", x, y, got.RGBAAt(x, y), want.RGBAAt(x, y))
+			}
+		}
+	}
+	return "sin diferencias"
+}
+
+// boxAverage calcula a mano el promedio por area de una reduccion entera
+func boxAverage(src *image.RGBA, kx, ky int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0,0, src.Rect.Dx() / kx, src.Rect.Dy() / ky))
+	area := uint32(kx * ky)
+
+	for y := range dst.Rect.Dy() {
+		for x := range dst.Rect.Dx() {
+			var sum [BPP]uint32
+			for by := range ky {
+				for bx := range kx {
+					p := src.RGBAAt(x * kx + bx, y * ky + by)
+					sum[0] += uint32(p.R); sum[1] += uint32(p.G); sum[2] += uint32(p.B); sum[3] += uint32(p.A)
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8((sum[0] + area/2) / area),
+				G: uint8((sum[1] + area/2) / area),
+				B: uint8((sum[2] + area/2) / area),
+				A: uint8((sum[3] + area/2) / area),
+			})
+		}
+	}
+	return dst
+}
+
+// TestScaleImageIntegerRatio comprueba que ScaleImage usa el promedio por area en las reducciones
+// enteras exactas con kernels, y que respeta NearestNeighbor
+func TestScaleImageIntegerRatio(t *testing.T) {
+	img := alphaImage(240, 180)
+
+	ratios := []struct{ kx, ky int }{{2, 2}, {3, 3}, {4, 4}, {5, 5}}
+	for _, ratio := range ratios {
+		size := image.Pt(240 / ratio.kx, 180 / ratio.ky)
+		scale := float64(size.X) / 240
+
+		for _, kernel := range []draw.Interpolator{draw.BiLinear, draw.CatmullRom} {
+			for _, workers := range []int{1, 4} {
+				src := NewCustomImage(img, image.Rect(0,0, size.X, size.Y), image.Point{}, kernel, UI_Settings{}.Default())
+				src.Workers = workers
+
+				got := src.ScaleImage(scale)
+				want := boxAverage(img, ratio.kx, ratio.ky)
+				if !got.Rect.Eq(want.Rect) || !bytes.Equal(got.Pix[:len(want.Pix)], want.Pix) {
+					t.Errorf("%dx%d, workers=%d: %s", ratio.kx, ratio.ky, workers, firstDiff(got, want))
+				}
+				PutReusableRGBA(got)
+			}
+		}
+
+		src := NewCustomImage(img, image.Rect(0,0, size.X, size.Y), image.Point{}, draw.NearestNeighbor, UI_Settings{}.Default())
+		got := src.ScaleImage(scale)
+		want := image.NewRGBA(image.Rect(0,0, size.X, size.Y))
+		draw.NearestNeighbor.Scale(want, want.Rect, img, img.Rect, draw.Over, nil)
+		if !bytes.Equal(got.Pix[:len(want.Pix)], want.Pix) { t.Errorf("NearestNeighbor %dx%d: %s", ratio.kx, ratio.ky, firstDiff(got, want)) }
+		PutReusableRGBA(got)
+	}
+}
+
+// TestScaleInterpolator comprueba cuando se elige el promedio por area
+func TestScaleInterpolator(t *testing.T) {
+	tests := []struct {
+		interpolator	draw.Interpolator
+		dr, sr			image.Rectangle
+		box				bool
+	}{
+		{draw.CatmullRom, image.Rect(0,0, 100, 50), image.Rect(0,0, 400, 200), true},
+		{draw.BiLinear, image.Rect(0,0, 100, 50), image.Rect(0,0, 200, 150), true},
+		{draw.CatmullRom, image.Rect(0,0, 100, 50), image.Rect(0,0, 401, 200), false},
+		{draw.CatmullRom, image.Rect(0,0, 400, 200), image.Rect(0,0, 100, 50), false},
+		{draw.NearestNeighbor, image.Rect(0,0, 100, 50), image.Rect(0,0, 400, 200), false},
+		{draw.ApproxBiLinear, image.Rect(0,0, 100, 50), image.Rect(0,0, 400, 200), false},
+	}
+
+	for _, test := range tests {
+		src := RenderImage{Interpolator: test.interpolator}
+		_, box := src.scaleInterpolator(test.dr, test.sr).(boxFilter)
+		if box != test.box { t.Errorf("%v -> %v: promedio por area = %v, se esperaba %v", test.sr, test.dr, box, test.box) }
+	}
+}
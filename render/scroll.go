@@ -0,0 +1,105 @@
+package terminal
+
+import (
+	"image"
+	"os"
+
+	"github.com/Leontas-9/terminal-go/ansi"
+)
+
+// placement devuelve, sin escalar la imagen, lo que haria prepareRender: los pixeles del
+// terminal que ocupa la imagen y el desplazamiento de la porcion visible (Viewport ya ajustado,
+// o (0,0) si la imagen cabe entera)
+func (src *RenderImage) placement() (at image.Rectangle, viewport image.Point, err error) {
+	view := *src
+	err = view.validateInputs()
+	if err != nil { return image.Rectangle{}, image.Point{}, err }
+
+	view.Margin, err = view.AdjustLimitsToTerminal()
+	if err != nil { return image.Rectangle{}, image.Point{}, err }
+
+	scale := view.CalculateScale()
+	size := view.scaledSize(scale)
+	if view.isPannable(scale) {
+		size = view.viewSize(scale)
+		viewport = view.clampViewport(view.Viewport, scale)
+	}
+
+	position := ClampToPoint(view.InitialPoint, cachedPixelSize().Sub(size))
+	return image.Rectangle{Min: position, Max: position.Add(size)}, viewport, nil
+}
+
+// sameView indica si dos estados de la imagen solo se diferencian en la posicion
+// (InitialPoint) y el desplazamiento de la vista (Viewport)
+// Los interpoladores no se comparan: el visor no los cambia y pueden no ser comparables
+func sameView(a, b *RenderImage) bool {
+	ta, tb := a.Transform, b.Transform
+	return a.Image == b.Image && a.Margin == b.Margin && a.Crop == b.Crop && a.Zoom == b.Zoom && a.opts == b.opts &&
+		ta.Rotation == tb.Rotation && ta.FlipH == tb.FlipH && ta.FlipV == tb.FlipV && ta.Angle == tb.Angle
+}
+
+// scroll redibuja un desplazamiento vertical de la imagen desplazando sus filas en el terminal
+// (region de desplazamiento, SU y SD) y dibujando solo las filas que quedan al descubierto,
+// mucho menos que redibujar todas las celdas en una conexion lenta
+// 'before' es el estado de la imagen dibujada; devuelve false si el cambio no es un
+// desplazamiento vertical de filas completas y hay que redibujar todo (ver draw)
+func (v *Viewer) scroll(src, before *RenderImage) bool {
+	if v.help || !sameView(src, before) { return false }
+
+	lastAt, lastViewport, err := before.placement()
+	if err != nil { return false }
+	at, viewport, err := src.placement()
+	if err != nil { return false }
+
+	// Misma columna y tamaño, filas de pixeles pares (cada celda son dos) y altura par,
+	// para que la ultima fila de celdas no quede a medias al desplazarse
+	if at.Min.X != lastAt.Min.X || at.Size() != lastAt.Size() || viewport.X != lastViewport.X { return false }
+	if at.Min.Y % PPB != 0 || lastAt.Min.Y % PPB != 0 || at.Dy() % PPB != 0 { return false }
+	if (viewport.Y - lastViewport.Y) % PPB != 0 { return false }
+
+	// Filas de celdas que baja el contenido y filas nuevas de la vista (negativo: arriba)
+	shift := (at.Min.Y - lastAt.Min.Y - (viewport.Y - lastViewport.Y)) / PPB
+	exposed := (viewport.Y - lastViewport.Y) / PPB
+
+	// Region: las filas que ocupaba la imagen y las que ocupa ahora
+	top := min(at.Min.Y, lastAt.Min.Y) / PPB
+	bottom := max(at.Max.Y, lastAt.Max.Y) / PPB
+	if max(shift, -shift) >= bottom - top { return false }
+
+	dst, err := src.prepareRender()
+	if err != nil { return false }
+	if !sharesPixels(dst.Image, src.Image) { defer PutReusableRGBA(dst.Image) }
+
+	buf := GetRenderBuffer()
+	defer PutRenderBuffer(buf)
+
+	buf.Write(resetColor)
+	if shift != 0 {
+		buf.WriteString(ansi.SetScrollRegion(top + 1, bottom))
+		if shift > 0 { buf.WriteString(ansi.ScrollDown(shift))
+		} else { buf.WriteString(ansi.ScrollUp(-shift)) }
+		buf.WriteString(ansi.ResetScrollRegion())
+	}
+
+	// Filas de la vista que no estaban en pantalla: abajo si la vista bajo, arriba si subio
+	rows := dst.Image.Rect.Dy() / PPB
+	from, to := 0, -exposed
+	if exposed > 0 { from, to = rows - exposed, rows }
+	if to > from {
+		part := dst
+		part.Image = dst.Image.SubImage(image.Rect(
+			dst.Image.Rect.Min.X, dst.Image.Rect.Min.Y + from * PPB,
+			dst.Image.Rect.Max.X, dst.Image.Rect.Min.Y + to * PPB,
+		)).(*image.RGBA)
+		part.InitialPoint.Y += from * PPB
+
+		err = part.renderTo(buf)
+		if err != nil { return false }
+	}
+
+	_, err = buf.WriteTo(os.Stdout)
+	if err != nil { return false }
+
+	if v.StatusLine { writeStatusLine(v.statusText(src, v.File), v.StatusStyle) }
+	return true
+}
@@ -0,0 +1,210 @@
+package terminal
+
+import (
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"syscall"
+
+	"github.com/Leontas-9/terminal-go/ansi"
+)
+
+// TerminalState registra los cambios que se hacen al terminal (cursor, pantalla alternativa,
+// ajuste de linea, raton, pegado delimitado, modo crudo) y los deshace en orden inverso
+// con Close, al llegar una señal de terminacion o en un panic (ver Recover)
+// Solo se restaura lo que cambio: si un modo ya tenia el valor pedido no se escribe nada.
+// Los estados se anidan como una pila: al cerrar el interior, el terminal queda como lo dejo el exterior
+type TerminalState struct {
+	mu		sync.Mutex
+	changes	[]stateChange
+	closed	bool
+	stop	func()
+}
+
+// stateChange es un cambio registrado: la funcion que lo deshace
+type stateChange struct {
+	restore	func() error
+}
+
+// Estado conocido del terminal, compartido por todos los TerminalState
+// Los modos que nunca se cambiaron tienen su valor habitual (cursor visible, ajuste de linea activo)
+var (
+	modesMu		sync.Mutex
+	modes		= map[int]bool{ansi.ModeCursorVisible: true, ansi.ModeAutoWrap: true}
+	openStates	[]*TerminalState
+)
+
+// terminalMode devuelve el estado actual de un modo segun los TerminalState abiertos
+func terminalMode(mode int) bool {
+	modesMu.Lock()
+	defer modesMu.Unlock()
+
+	return modes[mode]
+}
+
+// NewTerminalState crea un estado vacio que, ademas de con Close, se restaura al llegar
+// SIGINT, SIGTERM o SIGHUP: entonces cierra todos los estados abiertos (del ultimo al primero)
+// y termina el programa con el codigo 128 + señal
+func NewTerminalState() *TerminalState {
+	return newTerminalState(true)
+}
+
+// newTerminalState crea un estado; sin 'handleSignals' quien lo crea se encarga de las señales
+// (por ejemplo EventLoop, que las entrega como error y llama a Close)
+func newTerminalState(handleSignals bool) *TerminalState {
+	state := &TerminalState{stop: func() {}}
+
+	modesMu.Lock()
+	openStates = append(openStates, state)
+	modesMu.Unlock()
+
+	if handleSignals { state.stop = restoreOnSignal() }
+
+	return state
+}
+
+// restoreOnSignal cierra los estados abiertos y termina el programa al llegar una señal de terminacion
+// Devuelve la funcion que deja de escuchar las señales
+func restoreOnSignal() (stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-signals:
+			closeOpenStates()
+			code := 1
+			if number, ok := sig.(syscall.Signal); ok { code = 128 + int(number) }
+			os.Exit(code)
+		case <-done:
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	}
+}
+
+// closeOpenStates cierra todos los estados abiertos, del mas reciente al mas antiguo
+func closeOpenStates() {
+	modesMu.Lock()
+	states := slices.Clone(openStates)
+	modesMu.Unlock()
+
+	for _, state := range slices.Backward(states) { state.Close() }
+}
+
+// SetMode cambia un modo privado (DECSET), por ejemplo ansi.ModeAltScreen
+// No escribe nada si el modo ya tenia ese valor
+func (state *TerminalState) SetMode(mode int, isActive bool) error {
+	return state.setMode(mode, isActive, func(isActive bool) string { return ansi.PrivateMode(mode, isActive) })
+}
+
+// ShowCursor muestra u oculta el cursor
+func (state *TerminalState) ShowCursor(show bool) error {
+	return state.SetMode(ansi.ModeCursorVisible, show)
+}
+
+// AlternativeScreen cambia a la pantalla alternativa o vuelve a la principal
+func (state *TerminalState) AlternativeScreen(isActive bool) error {
+	return state.SetMode(ansi.ModeAltScreen, isActive)
+}
+
+// Auto_Wrap activa o desactiva el ajuste automatico de linea
+func (state *TerminalState) Auto_Wrap(isActive bool) error {
+	return state.SetMode(ansi.ModeAutoWrap, isActive)
+}
+
+// BracketedPaste activa o desactiva el pegado delimitado
+func (state *TerminalState) BracketedPaste(isActive bool) error {
+	return state.SetMode(ansi.ModeBracketedPaste, isActive)
+}
+
+// Mouse activa (EnableMouse) o desactiva (DisableMouse) el seguimiento del raton
+func (state *TerminalState) Mouse(isActive bool) error {
+	return state.setMode(ansi.MouseDrag, isActive, func(isActive bool) string {
+		if isActive { return ansi.EnableMouse() }
+		return ansi.DisableMouse()
+	})
+}
+
+// RawInput pasa la entrada del terminal a modo crudo; Close restaura el modo anterior
+func (state *TerminalState) RawInput() error {
+	restore, err := enableRawInput()
+	if err != nil { return err }
+
+	state.Push(restore)
+	return nil
+}
+
+// Push registra un cambio hecho fuera de TerminalState: 'restore' se llama al cerrar el estado,
+// en orden inverso al de los demas cambios
+func (state *TerminalState) Push(restore func() error) {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	state.changes = append(state.changes, stateChange{restore: restore})
+}
+
+// setMode escribe 'sequence(isActive)' si el modo cambia y registra como deshacerlo
+func (state *TerminalState) setMode(mode int, isActive bool, sequence func(bool) string) error {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	modesMu.Lock()
+	defer modesMu.Unlock()
+
+	previous := modes[mode]
+	if previous == isActive { return nil }
+
+	_, err := os.Stdout.WriteString(sequence(isActive))
+	if err != nil { return err }
+	modes[mode] = isActive
+
+	state.changes = append(state.changes, stateChange{
+		restore: func() error {
+			modes[mode] = previous
+			_, err := os.Stdout.WriteString(sequence(previous))
+			return err
+		},
+	})
+	return nil
+}
+
+// Close deshace los cambios del estado en orden inverso y devuelve el primer error
+// Se puede llamar varias veces; los cambios posteriores a Close tambien se deshacen con otro Close
+func (state *TerminalState) Close() (err error) {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	modesMu.Lock()
+	defer modesMu.Unlock()
+
+	for _, change := range slices.Backward(state.changes) {
+		if restoreErr := change.restore(); err == nil { err = restoreErr }
+	}
+	state.changes = state.changes[:0]
+
+	if !state.closed {
+		state.closed = true
+		openStates = slices.DeleteFunc(openStates, func(open *TerminalState) bool { return open == state })
+		state.stop()
+	}
+	return err
+}
+
+// Recover cierra el estado como Close y, si hubo un panic, lo relanza con el terminal ya restaurado
+// Se usa en lugar de Close: defer state.Recover()
+// Solo cubre los panics de la goroutine que lo difiere
+func (state *TerminalState) Recover() {
+	r := recover()
+	state.Close()
+
+	if r != nil { panic(r) }
+}
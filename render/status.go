@@ -0,0 +1,143 @@
+package terminal
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Leontas-9/terminal-go/ansi"
+)
+
+// ColorMode describe como se pintan los pixeles en el terminal (se muestra en la linea de estado)
+const ColorMode = "24-bit ▀▄"
+
+// statusText devuelve la linea de estado de la imagen: archivo, tamaño de origen,
+// zoom, desplazamiento, formato y modo de color
+// El desplazamiento es el de la vista si la imagen esta ampliada y si no el de la imagen
+func (v *Viewer) statusText(src *RenderImage, file string) string {
+	size := src.sourceSize()
+
+	offset := src.InitialPoint
+	if src.IsZoomed() { offset = src.Viewport }
+
+	fields := make([]string, 0, 6)
+	if file != "" {
+		name := filepath.Base(file)
+		if v.Hyperlinks { name = ansi.Hyperlink(ansi.FileURL(file), name) }
+		fields = append(fields, name)
+	}
+	fields = append(fields,
+		fmt.Sprintf("%dx%d", size.X, size.Y),
+		fmt.Sprintf("%.0f%%", src.currentScale() * 100),
+		fmt.Sprintf("%+d,%+d", offset.X, offset.Y),
+	)
+	if format := strings.TrimPrefix(filepath.Ext(file), "."); format != "" {
+		fields = append(fields, strings.ToUpper(format))
+	}
+	fields = append(fields, ColorMode)
+
+	return " " + strings.Join(fields, "  ") + " "
+}
+
+// reserveStatus quita de los bordes la ultima fila del terminal, donde va la linea de estado
+func reserveStatus(margin image.Rectangle) image.Rectangle {
+	size, err := GetTerminalPixelSize()
+	if err != nil { size = cachedPixelSize() }
+
+	limit := max(size.Y - PPB, PPB)
+	if margin.Empty() || margin.Max.Y > limit { margin.Max.Y = limit }
+	if margin.Empty() { margin = image.Rect(0,0, size.X, limit) }
+
+	return margin
+}
+
+// draw limpia la pantalla y dibuja la imagen, la linea de estado y la ayuda si estan activas
+func (v *Viewer) draw(src *RenderImage) error {
+	os.Stdout.Write(moveToStart)
+	os.Stdout.Write(eraseScreen_FromCursor)
+
+	_, err := src.Print()
+	if err != nil { return err }
+
+	v.setTitle(v.File)
+	if v.StatusLine { writeStatusLine(v.statusText(src, v.File), v.StatusStyle) }
+	if v.help { v.writeHelp() }
+
+	return nil
+}
+
+// helpLines devuelve una linea por accion con las teclas asignadas, mas las del raton
+func (v *Viewer) helpLines() []string {
+	keys := make(map[Action][]string)
+	for key, action := range v.Keys { keys[action] = append(keys[action], key) }
+
+	lines := make([]string, 0, len(actionNames) + 2)
+	for action := PanUp; int(action) < len(actionNames); action++ {
+		if len(keys[action]) == 0 { continue }
+
+		slices.Sort(keys[action])
+		lines = append(lines, fmt.Sprintf("%-20s %s", strings.Join(keys[action], ", "), action))
+	}
+
+	if v.Mouse {
+		lines = append(lines,
+			fmt.Sprintf("%-20s %s", "arrastrar", "pan"),
+			fmt.Sprintf("%-20s %s", "rueda", "zoom-in / zoom-out"),
+		)
+	}
+
+	return lines
+}
+
+// writeHelp dibuja la lista de teclas en un recuadro centrado sobre la imagen
+func (v *Viewer) writeHelp() {
+	size, err := GetTerminalSize()
+	if err != nil { return }
+
+	lines := v.helpLines()
+	width := 0
+	for _, line := range lines { width = max(width, ansi.StringWidth(line)) }
+	width = min(width + 2, size.X)
+
+	top := max((size.Y - len(lines)) / 2, 0) + 1
+	left := max((size.X - width) / 2, 0) + 1
+
+	var sb strings.Builder
+	for i, line := range lines {
+		if top + i > size.Y { break }
+
+		text := " " + ansi.Pad(line, width - 2, ansi.AlignLeft) + " "
+
+		sb.WriteString(ansi.MoveTo(left, top + i))
+		sb.WriteString(v.StatusStyle.PaintString(text, true))
+	}
+
+	os.Stdout.WriteString(sb.String())
+}
+
+// pushTitle guarda el titulo de la ventana (si Title esta activo) y devuelve la funcion que lo restaura
+func (v *Viewer) pushTitle() (pop func()) {
+	if !v.Title { return func() {} }
+
+	os.Stdout.WriteString(ansi.PushTitle())
+	return func() { os.Stdout.WriteString(ansi.PopTitle()) }
+}
+
+// setTitle cambia el titulo de la ventana al nombre del archivo (si Title esta activo)
+func (v *Viewer) setTitle(file string) {
+	if !v.Title || file == "" { return }
+
+	os.Stdout.WriteString(ansi.SetWindowTitle(filepath.Base(file)))
+}
+
+// copyPath copia la ruta absoluta del archivo al portapapeles del terminal (OSC 52)
+// Dentro de tmux la secuencia se envuelve para que llegue al terminal exterior
+func (v *Viewer) copyPath(file string) {
+	if file == "" { return }
+
+	if absolute, err := filepath.Abs(file); err == nil { file = absolute }
+	os.Stdout.WriteString(ansi.Passthrough(ansi.SetClipboard(file)))
+}
@@ -0,0 +1,121 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package terminal
+
+import (
+	"image"
+	"io"
+	"os"
+	"os/signal"
+
+	"golang.org/x/sys/unix"
+)
+
+// Retorna el punto maximo del tamaño actual del terminal
+// La cantidad de caracteres de ancho y largo que se pueden utilizar
+func GetTerminalSize() (size image.Point, err error) {
+	winsize, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil { return image.Point{}, err }
+
+	return image.Pt(int(winsize.Col), int(winsize.Row)), nil
+}
+
+// enableRawInput pasa el terminal a modo crudo (como cfmakeraw, pero conservando
+// el procesado de la salida) y devuelve la funcion que restaura el modo anterior
+func enableRawInput() (restore func() error, err error) {
+	fd := int(os.Stdin.Fd())
+
+	previous, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil { return nil, err }
+
+	raw := *previous
+	raw.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	raw.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	raw.Cflag &^= unix.CSIZE | unix.PARENB
+	raw.Cflag |= unix.CS8
+	raw.Cc[unix.VMIN] = 1
+	raw.Cc[unix.VTIME] = 0
+
+	err = unix.IoctlSetTermios(fd, ioctlSetTermios, &raw)
+	if err != nil { return nil, err }
+
+	return func() error { return unix.IoctlSetTermios(fd, ioctlSetTermios, previous) }, nil
+}
+
+// stdinReader lee la entrada estandar hasta que se cancela: espera a la vez la entrada
+// y una tuberia propia, en la que cancel escribe para despertar la espera
+type stdinReader struct {
+	fd		int
+	pipe	[2]int
+}
+
+// newStdinReader crea un lector de la entrada estandar que se puede cancelar
+func newStdinReader() (*stdinReader, error) {
+	reader := &stdinReader{fd: int(os.Stdin.Fd())}
+
+	err := unix.Pipe(reader.pipe[:])
+	if err != nil { return nil, err }
+
+	unix.CloseOnExec(reader.pipe[0])
+	unix.CloseOnExec(reader.pipe[1])
+
+	return reader, nil
+}
+
+// Read espera a que haya entrada y la lee; devuelve errInputClosed si se llamo a cancel
+func (reader *stdinReader) Read(p []byte) (n int, err error) {
+	for {
+		ready, err := waitInput(reader.fd, reader.pipe[0])
+		if err == unix.EINTR { continue }
+		if err != nil { return 0, err }
+		if !ready { return 0, errInputClosed }
+
+		n, err = unix.Read(reader.fd, p)
+		if err == unix.EINTR || err == unix.EAGAIN { continue }
+		if err != nil { return 0, err }
+		if n == 0 { return 0, io.EOF }
+
+		return n, nil
+	}
+}
+
+// cancel despierta la espera de Read, que devuelve errInputClosed (tambien las siguientes)
+func (reader *stdinReader) cancel() {
+	unix.Write(reader.pipe[1], []byte{0})
+}
+
+// close libera la tuberia; solo se llama cuando ya nadie espera en Read
+func (reader *stdinReader) close() error {
+	err := unix.Close(reader.pipe[0])
+	if closeErr := unix.Close(reader.pipe[1]); err == nil { err = closeErr }
+
+	return err
+}
+
+// resizeSignals avisa de cada SIGWINCH; stop deja de escuchar la señal
+func resizeSignals() (signals <-chan struct{}, stop func()) {
+	received := make(chan os.Signal, 1)
+	signal.Notify(received, unix.SIGWINCH)
+
+	notify := make(chan struct{}, 1)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-received:
+				select {
+				case notify <- struct{}{}:
+				default:
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return notify, func() {
+		signal.Stop(received)
+		close(done)
+	}
+}
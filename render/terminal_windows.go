@@ -0,0 +1,185 @@
+package terminal
+
+import (
+	"encoding/binary"
+	"image"
+	"slices"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Retorna el punto maximo del tamaño actual del terminal
+// La cantidad de caracteres de ancho y largo que se pueden utilizar
+func GetTerminalSize() (size image.Point, err error) {
+	var info windows.ConsoleScreenBufferInfo
+	handle := windows.Handle(windows.Stdout)
+	err = windows.GetConsoleScreenBufferInfo(handle, &info)
+	if err != nil {return image.Point{}, err}
+	
+	ancho := int(info.Window.Right - info.Window.Left + 1)
+	alto := int(info.Window.Bottom - info.Window.Top + 1)
+
+
+	size = image.Pt(ancho, alto)
+	return size, nil
+}
+
+// Modo de la consola para leer la entrada como secuencias VT (teclas y raton) y los cambios
+// de tamaño (WINDOW_BUFFER_SIZE_EVENT), sin eco, sin esperar a Enter y sin que Ctrl+C
+// ni la edicion rapida la intercepten
+const (
+	rawInputOff = windows.ENABLE_ECHO_INPUT | windows.ENABLE_LINE_INPUT | windows.ENABLE_PROCESSED_INPUT |
+		windows.ENABLE_QUICK_EDIT_MODE | windows.ENABLE_MOUSE_INPUT
+	rawInputOn = windows.ENABLE_VIRTUAL_TERMINAL_INPUT | windows.ENABLE_EXTENDED_FLAGS | windows.ENABLE_WINDOW_INPUT
+)
+
+// enableRawInput pasa la consola a modo crudo y devuelve la funcion que restaura el modo anterior
+func enableRawInput() (restore func() error, err error) {
+	handle := windows.Handle(windows.Stdin)
+
+	var mode uint32
+	err = windows.GetConsoleMode(handle, &mode)
+	if err != nil { return nil, err }
+
+	err = windows.SetConsoleMode(handle, mode &^ rawInputOff | rawInputOn)
+	if err != nil { return nil, err }
+
+	return func() error { return windows.SetConsoleMode(handle, mode) }, nil
+}
+
+// ReadConsoleInputW no esta en x/sys/windows
+var procReadConsoleInput = windows.NewLazySystemDLL("kernel32.dll").NewProc("ReadConsoleInputW")
+
+// inputRecord es un INPUT_RECORD de la consola; Event es la union de los registros
+type inputRecord struct {
+	EventType	uint16
+	_			uint16
+	Event		[16]byte
+}
+
+// readConsoleInput lee registros de la cola de entrada de la consola (bloquea si esta vacia)
+func readConsoleInput(handle windows.Handle, records []inputRecord) (n uint32, err error) {
+	r1, _, e1 := procReadConsoleInput.Call(uintptr(handle), uintptr(unsafe.Pointer(&records[0])), uintptr(len(records)), uintptr(unsafe.Pointer(&n)))
+	if r1 == 0 { return 0, e1 }
+
+	return n, nil
+}
+
+// stdinReader lee la cola de entrada de la consola hasta que se cancela: espera a la vez
+// la consola y un evento propio, que cancel activa para despertar la espera
+// Los caracteres de las pulsaciones se entregan como UTF-8 y los cambios de tamaño
+// se avisan a resizeSignals
+type stdinReader struct {
+	handle		windows.Handle
+	canceled	windows.Handle
+	records		[64]inputRecord
+	pending		[]byte
+	surrogate	rune
+}
+
+// newStdinReader crea un lector de la consola que se puede cancelar
+func newStdinReader() (*stdinReader, error) {
+	canceled, err := windows.CreateEvent(nil, 1, 0, nil)
+	if err != nil { return nil, err }
+
+	return &stdinReader{handle: windows.Handle(windows.Stdin), canceled: canceled}, nil
+}
+
+// Read espera a que haya entrada y la lee; devuelve errInputClosed si se llamo a cancel
+func (reader *stdinReader) Read(p []byte) (n int, err error) {
+	for len(reader.pending) == 0 {
+		event, err := windows.WaitForMultipleObjects([]windows.Handle{reader.canceled, reader.handle}, false, windows.INFINITE)
+		if err != nil { return 0, err }
+		if event == windows.WAIT_OBJECT_0 { return 0, errInputClosed }
+
+		count, err := readConsoleInput(reader.handle, reader.records[:])
+		if err != nil { return 0, err }
+
+		for _, record := range reader.records[:count] { reader.decode(record) }
+	}
+
+	n = copy(p, reader.pending)
+	reader.pending = reader.pending[n:]
+	return n, nil
+}
+
+// decode agrega a pending los caracteres de una pulsacion y avisa de los cambios de tamaño
+func (reader *stdinReader) decode(record inputRecord) {
+	switch record.EventType {
+	case windows.WINDOW_BUFFER_SIZE_EVENT:
+		notifyResize()
+	case windows.KEY_EVENT:
+		// KEY_EVENT_RECORD: bKeyDown (4 bytes), wRepeatCount, wVirtualKeyCode, wVirtualScanCode,
+		// UnicodeChar (2 bytes cada uno) y dwControlKeyState. Con ENABLE_VIRTUAL_TERMINAL_INPUT
+		// cada caracter de las secuencias VT llega como una pulsacion con UnicodeChar
+		keyDown := binary.LittleEndian.Uint32(record.Event[0:4])
+		repeat := binary.LittleEndian.Uint16(record.Event[4:6])
+		char := binary.LittleEndian.Uint16(record.Event[10:12])
+		if keyDown == 0 || char == 0 { return }
+
+		r := rune(char)
+		if utf16.IsSurrogate(r) {
+			// Los caracteres fuera del plano basico llegan en dos pulsaciones
+			if reader.surrogate == 0 {
+				reader.surrogate = r
+				return
+			}
+			r = utf16.DecodeRune(reader.surrogate, r)
+		}
+		reader.surrogate = 0
+
+		for range max(repeat, 1) { reader.pending = utf8.AppendRune(reader.pending, r) }
+	}
+}
+
+// cancel despierta la espera de Read, que devuelve errInputClosed (tambien las siguientes)
+func (reader *stdinReader) cancel() {
+	windows.SetEvent(reader.canceled)
+}
+
+// close libera el evento de cancelacion; solo se llama cuando ya nadie espera en Read
+func (reader *stdinReader) close() error {
+	return windows.CloseHandle(reader.canceled)
+}
+
+// Los WINDOW_BUFFER_SIZE_EVENT llegan por la cola de entrada de la consola, que solo se lee
+// mientras hay una sesion de entrada (un EventLoop): fuera de ella la entrada es del programa
+var resizeListeners struct {
+	mu		sync.Mutex
+	list	[]chan struct{}
+}
+
+// notifyResize avisa de un cambio de tamaño a todos los resizeSignals activos
+func notifyResize() {
+	resizeListeners.mu.Lock()
+	defer resizeListeners.mu.Unlock()
+
+	for _, notify := range resizeListeners.list {
+		select {
+		case notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// resizeSignals avisa de cada WINDOW_BUFFER_SIZE_EVENT que lee la sesion de entrada; stop deja de escuchar
+func resizeSignals() (signals <-chan struct{}, stop func()) {
+	notify := make(chan struct{}, 1)
+
+	resizeListeners.mu.Lock()
+	resizeListeners.list = append(resizeListeners.list, notify)
+	resizeListeners.mu.Unlock()
+
+	var once sync.Once
+	return notify, func() {
+		once.Do(func() {
+			resizeListeners.mu.Lock()
+			resizeListeners.list = slices.DeleteFunc(resizeListeners.list, func(listener chan struct{}) bool { return listener == notify })
+			resizeListeners.mu.Unlock()
+		})
+	}
+}
@@ -0,0 +1,9 @@
+package terminal
+
+import "golang.org/x/sys/unix"
+
+// Peticiones ioctl para leer y escribir la configuracion del terminal
+const (
+	ioctlGetTermios = unix.TCGETS
+	ioctlSetTermios = unix.TCSETS
+)
@@ -0,0 +1,172 @@
+package terminal
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+)
+
+// Transform describe los giros y espejos que se aplican a la imagen (o al recorte)
+// antes de escalarla. El orden es: giros de 90°, espejos y por ultimo el angulo libre
+type Transform struct {
+	// Giros de 90° en sentido horario (se normaliza a 0, 1, 2 o 3)
+	Rotation		int
+
+	// Espejo horizontal (izquierda <-> derecha)
+	FlipH			bool
+
+	// Espejo vertical (arriba <-> abajo)
+	FlipV			bool
+
+	// Rotacion adicional en grados, en sentido horario
+	Angle			float64
+
+	// Interpolador usado para Angle (nil -> draw.BiLinear)
+	Interpolator	draw.Interpolator
+
+	// Color con el que se rellenan las esquinas que deja Angle
+	Background		color.RGBA
+}
+
+// IsIdentity indica si la transformacion deja la imagen sin cambios
+func (t Transform) IsIdentity() bool {
+	return t.quarters() == 0 && !t.FlipH && !t.FlipV && math.Mod(t.Angle, 360) == 0
+}
+
+// Size devuelve el tamaño que tendra la region 'bounds' despues de transformarla
+func (t Transform) Size(bounds image.Rectangle) image.Point {
+	size := bounds.Size()
+	if t.quarters() & 1 == 1 { size = image.Pt(size.Y, size.X) }
+	if math.Mod(t.Angle, 360) == 0 { return size }
+
+	sin, cos := math.Sincos(t.Angle * math.Pi / 180)
+	width, height := float64(size.X), float64(size.Y)
+
+	return image.Pt(
+		int(math.Ceil(math.Abs(width*cos) + math.Abs(height*sin) - 1e-9)),
+		int(math.Ceil(math.Abs(width*sin) + math.Abs(height*cos) - 1e-9)),
+	)
+}
+
+// Apply devuelve una imagen nueva del pool con la region 'bounds' de src transformada
+// Se debe devolver con PutReusableRGBA al terminar de usarla
+func (t Transform) Apply(src *image.RGBA, bounds image.Rectangle) *image.RGBA {
+	dst := t.applyOrientation(src, bounds)
+	if math.Mod(t.Angle, 360) == 0 { return dst }
+
+	rotated := t.applyAngle(dst)
+	PutReusableRGBA(dst)
+
+	return rotated
+}
+
+// applyOrientation aplica los giros de 90° y los espejos copiando pixel a pixel
+func (t Transform) applyOrientation(src *image.RGBA, bounds image.Rectangle) *image.RGBA {
+	size := Transform{Rotation: t.Rotation}.Size(bounds)
+	dst := GetReusableRGBA(image.Rect(0,0, size.X, size.Y))
+	width, height := bounds.Dx(), bounds.Dy()
+
+	for y := range size.Y {
+		d := y * dst.Stride
+		for x := range size.X {
+			// Se deshacen primero los espejos y luego el giro
+			fx, fy := x, y
+			if t.FlipH { fx = size.X - 1 - fx }
+			if t.FlipV { fy = size.Y - 1 - fy }
+
+			var sx, sy int
+			switch t.quarters() {
+			case 0: sx, sy = fx, fy
+			case 1: sx, sy = fy, height - 1 - fx
+			case 2: sx, sy = width - 1 - fx, height - 1 - fy
+			case 3: sx, sy = width - 1 - fy, fx
+			}
+
+			s := src.PixOffset(bounds.Min.X + sx, bounds.Min.Y + sy)
+			copy(dst.Pix[d:d+BPP], src.Pix[s:s+BPP])
+			d += BPP
+		}
+	}
+
+	return dst
+}
+
+// applyAngle gira la imagen un angulo libre alrededor de su centro
+// El resultado ocupa el rectangulo que contiene la imagen girada, con el fondo en Background
+func (t Transform) applyAngle(src *image.RGBA) *image.RGBA {
+	size := Transform{Angle: t.Angle}.Size(src.Rect)
+	dst := GetReusableRGBA(image.Rect(0,0, size.X, size.Y))
+	draw.Draw(dst, dst.Rect, image.NewUniform(t.Background), image.Point{}, draw.Src)
+
+	interpolator := t.Interpolator
+	if interpolator == nil { interpolator = draw.BiLinear }
+
+	sin, cos := math.Sincos(t.Angle * math.Pi / 180)
+	srcX, srcY := float64(src.Rect.Min.X) + float64(src.Rect.Dx())/2, float64(src.Rect.Min.Y) + float64(src.Rect.Dy())/2
+	dstX, dstY := float64(size.X)/2, float64(size.Y)/2
+
+	// Con el eje Y hacia abajo esta matriz gira en sentido horario
+	matrix := f64.Aff3{
+		cos, -sin, dstX - (cos*srcX - sin*srcY),
+		sin, cos, dstY - (sin*srcX + cos*srcY),
+	}
+	interpolator.Transform(dst, matrix, src, src.Rect, draw.Over, nil)
+
+	return dst
+}
+
+// quarters normaliza Rotation al rango [0, 3]
+func (t Transform) quarters() int {
+	return ((t.Rotation % 4) + 4) % 4
+}
+
+// RotateClockwise gira la imagen 90° en sentido horario
+func (src *RenderImage) RotateClockwise() {
+	src.rotateQuarter(1)
+}
+
+// RotateCounterClockwise gira la imagen 90° en sentido antihorario
+func (src *RenderImage) RotateCounterClockwise() {
+	src.rotateQuarter(-1)
+}
+
+// rotateQuarter gira la imagen tal como se ve en pantalla
+// Como los espejos se aplican despues del giro, un giro de 90° con un solo
+// espejo activo equivale al giro con el espejo del otro eje
+func (src *RenderImage) rotateQuarter(direction int) {
+	if src.Transform.FlipH != src.Transform.FlipV {
+		src.Transform.FlipH, src.Transform.FlipV = src.Transform.FlipV, src.Transform.FlipH
+	}
+	src.Transform.Rotation = (src.Transform.quarters() + direction + 4) % 4
+}
+
+// FlipHorizontal invierte la imagen de izquierda a derecha
+func (src *RenderImage) FlipHorizontal() {
+	src.Transform.FlipH = !src.Transform.FlipH
+}
+
+// FlipVertical invierte la imagen de arriba a abajo
+func (src *RenderImage) FlipVertical() {
+	src.Transform.FlipV = !src.Transform.FlipV
+}
+
+// SetAngle fija una rotacion libre en grados (sentido horario), el interpolador
+// con el que se calcula y el color de relleno de las esquinas
+func (src *RenderImage) SetAngle(degrees float64, interpolator draw.Interpolator, background color.RGBA) {
+	src.Transform.Angle			= degrees
+	src.Transform.Interpolator	= interpolator
+	src.Transform.Background	= background
+}
+
+// SetTransform cambia la transformacion aplicada a la imagen
+func (src *RenderImage) SetTransform(new Transform) {
+	src.Transform = new
+}
+
+// sourceSize devuelve el tamaño de la region renderizada despues de transformarla
+func (src *RenderImage) sourceSize() image.Point {
+	return src.Transform.Size(src.SourceBounds())
+}
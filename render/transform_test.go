@@ -0,0 +1,118 @@
+package terminal
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+// labeledImage crea una imagen de 5x4 rellena de 'x' con la region (1,1)-(4,3) etiquetada
+//
+//	a b c
+//	d e f
+//
+// La etiqueta de cada pixel va en el canal R
+func labeledImage() (*image.RGBA, image.Rectangle) {
+	img := image.NewRGBA(image.Rect(0,0, 5, 4))
+	for y := range 4 {
+		for x := range 5 { img.SetRGBA(x, y, color.RGBA{R: 'x', A: 255}) }
+	}
+
+	region := image.Rect(1,1, 4,3)
+	for i, label := range "abcdef" {
+		img.SetRGBA(region.Min.X + i % 3, region.Min.Y + i / 3, color.RGBA{R: uint8(label), A: 255})
+	}
+	return img, region
+}
+
+// labels devuelve las etiquetas de la imagen por filas, separadas por '/'
+func labels(img *image.RGBA) string {
+	rows := make([]string, img.Rect.Dy())
+	for y := range rows {
+		var row []byte
+		for x := range img.Rect.Dx() { row = append(row, img.RGBAAt(img.Rect.Min.X + x, img.Rect.Min.Y + y).R) }
+		rows[y] = string(row)
+	}
+	return strings.Join(rows, "/")
+}
+
+// TestTransformOrientation comprueba donde acaba cada pixel con los giros de 90° y los espejos
+func TestTransformOrientation(t *testing.T) {
+	tests := []struct {
+		name		string
+		transform	Transform
+		want		string
+	}{
+		{"identidad", Transform{}, "abc/def"},
+		{"giro horario", Transform{Rotation: 1}, "da/eb/fc"},
+		{"media vuelta", Transform{Rotation: 2}, "fed/cba"},
+		{"giro antihorario", Transform{Rotation: 3}, "cf/be/ad"},
+		{"Rotation negativa", Transform{Rotation: -1}, "cf/be/ad"},
+		{"Rotation mayor que 3", Transform{Rotation: 5}, "da/eb/fc"},
+		{"FlipH", Transform{FlipH: true}, "cba/fed"},
+		{"FlipV", Transform{FlipV: true}, "def/abc"},
+		{"FlipH y FlipV", Transform{FlipH: true, FlipV: true}, "fed/cba"},
+
+		// Los espejos se aplican despues del giro
+		{"giro horario y FlipH", Transform{Rotation: 1, FlipH: true}, "ad/be/cf"},
+		{"giro horario y FlipV", Transform{Rotation: 1, FlipV: true}, "fc/eb/da"},
+		{"giro antihorario y FlipH", Transform{Rotation: 3, FlipH: true}, "fc/eb/da"},
+		{"giro horario y ambos espejos", Transform{Rotation: 1, FlipH: true, FlipV: true}, "cf/be/ad"},
+	}
+
+	src, region := labeledImage()
+	for _, test := range tests {
+		got := test.transform.Apply(src, region)
+		if got.Rect.Min != (image.Point{}) { t.Errorf("%s: origen %v, se esperaba (0,0)", test.name, got.Rect.Min) }
+		if size := test.transform.Size(region); got.Rect.Size() != size { t.Errorf("%s: tamaño %v, Size devuelve %v", test.name, got.Rect.Size(), size) }
+		if labels(got) != test.want { t.Errorf("%s: %s, se esperaba %s", test.name, labels(got), test.want) }
+		PutReusableRGBA(got)
+	}
+}
+
+// TestTransformSize comprueba el tamaño con giros de 90° y con un angulo libre
+func TestTransformSize(t *testing.T) {
+	tests := []struct {
+		transform	Transform
+		bounds		image.Rectangle
+		want		image.Point
+	}{
+		{Transform{}, image.Rect(1,1, 4,3), image.Pt(3, 2)},
+		{Transform{Rotation: 1}, image.Rect(1,1, 4,3), image.Pt(2, 3)},
+		{Transform{Rotation: 2}, image.Rect(1,1, 4,3), image.Pt(3, 2)},
+		{Transform{Angle: 360}, image.Rect(0,0, 10,4), image.Pt(10, 4)},
+		{Transform{Angle: 90}, image.Rect(0,0, 10,4), image.Pt(4, 10)},
+		{Transform{Angle: 45}, image.Rect(0,0, 10,10), image.Pt(15, 15)},
+		{Transform{Angle: -45}, image.Rect(0,0, 10,10), image.Pt(15, 15)},
+		{Transform{Angle: 45}, image.Rect(1,1, 4,3), image.Pt(4, 4)},
+		{Transform{Rotation: 1, Angle: 45}, image.Rect(1,1, 4,3), image.Pt(4, 4)},
+	}
+
+	for _, test := range tests {
+		if got := test.transform.Size(test.bounds); got != test.want {
+			t.Errorf("%+v.Size(%v) = %v, se esperaba %v", test.transform, test.bounds, got, test.want)
+		}
+	}
+}
+
+// TestTransformAngle gira 45° un cuadrado opaco: las esquinas que quedan fuera se
+// rellenan con Background y el centro conserva el color de la imagen
+func TestTransformAngle(t *testing.T) {
+	green := color.RGBA{G: 200, A: 255}
+	background := color.RGBA{R: 30, B: 60, A: 255}
+
+	src := image.NewRGBA(image.Rect(0,0, 10, 10))
+	for i := 0; i < len(src.Pix); i += BPP { copy(src.Pix[i:i+BPP], []uint8{green.R, green.G, green.B, green.A}) }
+
+	transform := Transform{Angle: 45, Background: background}
+	got := transform.Apply(src, src.Rect)
+	defer PutReusableRGBA(got)
+
+	if got.Rect != image.Rect(0,0, 15, 15) { t.Fatalf("rectangulo %v, se esperaba (0,0)-(15,15)", got.Rect) }
+
+	for _, corner := range []image.Point{{0, 0}, {14, 0}, {0, 14}, {14, 14}} {
+		if pixel := got.RGBAAt(corner.X, corner.Y); pixel != background { t.Errorf("esquina %v = %v, se esperaba el fondo %v", corner, pixel, background) }
+	}
+	if pixel := got.RGBAAt(7, 7); pixel != green { t.Errorf("centro = %v, se esperaba %v", pixel, green) }
+}
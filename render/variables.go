@@ -13,10 +13,9 @@ import (
 var (
 	moveToStart = []byte (ansi.MoveToStart())
 	eraseScreen_FromCursor = []byte (ansi.EraseScreen_FromCursor())
-	alternativeScreen_On = []byte (ansi.AlternativeScreen(true))
-	alternativeScreen_Off = []byte (ansi.AlternativeScreen(false))
 	resetColor = []byte (ansi.ResetAllColors())
 	moveDown = []byte (ansi.MoveDown_Start(1))
+	moveRight = []byte (ansi.MoveRight(1))
 	upperBlock = ansi.UpperHalfBlock
 	lowerBlock = ansi.LowerHalfBlock
 )
@@ -41,9 +40,3 @@ const (
 	BPP = 4	// Bytes por Pixel (RGBA)
 	PPB = 2 // Pixeles por Bloque, Unicode(inferior  '▄' y superior '▀')
 )
-
-// Precañculo de puntos impares
-// determina si el alto de la imagen es impar o par
-var (
-	isYOdd bool
-)
\ No newline at end of file
@@ -0,0 +1,246 @@
+package terminal
+
+import (
+	"context"
+	"errors"
+	"image"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/Leontas-9/terminal-go/ansi"
+)
+
+// Viewer controla los modos interactivos (Displacement, Gallery y Grid):
+// traduce las teclas en acciones con su KeyMap y aplica el raton y el desplazamiento
+type Viewer struct {
+	// Asignacion de teclas a acciones
+	Keys		KeyMap
+
+	// Desplazamiento (en pixeles de terminal) de cada pulsacion
+	Step		int
+
+	// Desplazamiento maximo al mantener pulsada una tecla (0 -> sin aceleracion)
+	MaxStep		int
+
+	// Intervalo maximo entre dos pulsaciones de la misma accion para que cuenten
+	// como repeticion; cada repeatsPerStep repeticiones el desplazamiento crece un Step
+	Speed		time.Duration
+
+	// Activa el raton: arrastrar desplaza y la rueda cambia el zoom
+	Mouse		bool
+
+	// Muestra en la ultima fila el archivo, tamaño, zoom, desplazamiento, formato
+	// y modo de color; esa fila se quita de los bordes de la imagen
+	StatusLine	bool
+
+	// Archivo de la imagen, para la linea de estado (opcional)
+	File		string
+
+	// Estilo de la linea de estado y de la ayuda
+	StatusStyle	ansi.Style
+
+	// El nombre del archivo en la linea de estado es un enlace file:// (OSC 8)
+	Hyperlinks	bool
+
+	// Cambia el titulo de la ventana al nombre de la imagen y al salir restaura el anterior
+	Title		bool
+
+	// Estado del arrastre, de la repeticion de teclas y de la ayuda (Help)
+	drag		dragState
+	repeat		repeatState
+	help		bool
+}
+
+// repeatsPerStep es la cantidad de repeticiones seguidas que suman un Step al desplazamiento
+const repeatsPerStep = 4
+
+// dragState guarda la ultima celda del puntero mientras se arrastra la imagen
+type dragState struct {
+	from	image.Point
+	active	bool
+}
+
+// repeatState guarda la ultima accion de desplazamiento para acelerar al repetirla
+type repeatState struct {
+	action	Action
+	at		time.Time
+	count	int
+}
+
+// NewViewer crea un visor con las teclas por defecto, StepsDistance y StepSpeed
+func NewViewer() *Viewer {
+	return &Viewer{
+		Keys:		DefaultKeyMap(),
+		Step:		StepsDistance,
+		MaxStep:	StepsDistance * 8,
+		Speed:		StepSpeed,
+		Mouse:		true,
+		StatusStyle:	DefaultStatusStyle,
+		Hyperlinks:	true,
+		Title:		true,
+	}
+}
+
+// LoadViewer crea un visor (NewViewer) y le aplica el archivo DefaultConfigPath si existe
+func LoadViewer() (*Viewer, error) {
+	viewer := NewViewer()
+
+	path, err := DefaultConfigPath()
+	if err != nil { return viewer, nil }
+
+	err = viewer.LoadConfig(path)
+	if errors.Is(err, fs.ErrNotExist) { return viewer, nil }
+
+	return viewer, err
+}
+
+// Show muestra la imagen en la pantalla alternativa hasta la accion Quit
+func (v *Viewer) Show(src *RenderImage) error {
+	return v.ShowContext(context.Background(), src)
+}
+
+// ShowContext es como Show, pero tambien termina (con el error del contexto) al cancelarse ctx
+func (v *Viewer) ShowContext(ctx context.Context, src *RenderImage) error {
+	loop, err := StartEventLoop(ctx, EventOptions{Mouse: v.Mouse, AlternativeScreen: true})
+	if err != nil { return err }
+	defer loop.Close()
+	defer v.pushTitle()()
+
+	return v.interact(src, loop)
+}
+
+// interact dibuja la imagen y aplica los eventos del bucle hasta la accion Quit
+// No cambia de pantalla ni de modo de entrada: lo hace quien la llama
+func (v *Viewer) interact(src *RenderImage, loop *EventLoop) error {
+	margin := src.Margin
+	if v.StatusLine {
+		defer func() { src.Margin = margin }()
+		src.Margin = reserveStatus(margin)
+	}
+
+	err := v.draw(src)
+	if err != nil {return err}
+
+	lastPosition := src.InitialPoint
+
+	for event := range loop.Events() {
+		switch event.Kind {
+		case EventResize:
+			// Con el nuevo tamaño se vuelve a reservar la linea de estado y se redibuja
+			// (prepareRender ajusta los bordes y la posicion al terminal)
+			if v.StatusLine { src.Margin = reserveStatus(margin) }
+			err = v.draw(src)
+			if err != nil { return err }
+		case EventInput:
+			if v.Action(event.Input) == Quit {
+				os.Stdout.Write(moveToStart)
+				os.Stdout.Write(eraseScreen_FromCursor)
+				return nil
+			}
+
+			before := *src
+			if v.apply(src, event.Input) || !lastPosition.Eq(src.InitialPoint) {
+				lastPosition = src.InitialPoint
+				if v.scroll(src, &before) { continue }
+
+				err = v.draw(src)
+				if err != nil { return err }
+			}
+		}
+	}
+
+	return loop.Err()
+}
+
+// Action devuelve la accion asociada al evento (ActionNone para el raton y las teclas sin asignar)
+func (v *Viewer) Action(event ansi.InputEvent) Action {
+	key, ok := event.(ansi.KeyEvent)
+	if !ok { return ActionNone }
+
+	return v.Keys.Lookup(key)
+}
+
+// apply aplica el evento a la imagen y devuelve true si hay que redibujarla
+func (v *Viewer) apply(src *RenderImage, event ansi.InputEvent) bool {
+	if mouse, ok := event.(ansi.MouseEvent); ok { return v.applyMouse(src, mouse) }
+
+	return v.Apply(src, v.Action(event))
+}
+
+// Apply aplica una accion a la imagen y devuelve true si hay que redibujarla
+// Con la imagen ampliada (mas grande que los bordes) los desplazamientos mueven la vista
+// dentro de la imagen; en otro caso mueven la imagen dentro del terminal
+// Help muestra u oculta la lista de teclas y CopyPath copia la ruta de File al portapapeles;
+// las acciones de navegacion (Next, Open, Quit...) no cambian la imagen
+func (v *Viewer) Apply(src *RenderImage, action Action) bool {
+	// Cualquier otra accion corta la repeticion: el siguiente desplazamiento empieza sin acelerar
+	if !action.isPan() { v.repeat = repeatState{} }
+
+	switch action {
+	case ZoomIn:					src.ZoomIn()
+	case ZoomOut:					src.ZoomOut()
+	case ZoomToFit:					src.ZoomToFit()
+	case ZoomActual:				src.ZoomActual()
+	case RotateClockwise:			src.RotateClockwise()
+	case RotateCounterClockwise:	src.RotateCounterClockwise()
+	case FlipHorizontal:			src.FlipHorizontal()
+	case FlipVertical:				src.FlipVertical()
+	case Help:						v.help = !v.help
+	case CopyPath:
+		v.copyPath(v.File)
+		return false
+	case PanUp:						return src.pan(0, -v.step(action))
+	case PanDown:					return src.pan(0, v.step(action))
+	case PanLeft:					return src.pan(-v.step(action), 0)
+	case PanRight:					return src.pan(v.step(action), 0)
+	default:						return false
+	}
+	return true
+}
+
+// step devuelve el desplazamiento de la accion: Step, mas un Step cada repeatsPerStep
+// repeticiones seguidas (separadas por menos de Speed), hasta MaxStep
+func (v *Viewer) step(action Action) int {
+	now := time.Now()
+
+	if action == v.repeat.action && now.Sub(v.repeat.at) <= v.Speed {
+		v.repeat.count++
+	} else {
+		v.repeat.count = 0
+	}
+	v.repeat.action, v.repeat.at = action, now
+
+	step := v.Step * (1 + v.repeat.count / repeatsPerStep)
+	if v.MaxStep > v.Step { step = min(step, v.MaxStep) } else { step = v.Step }
+
+	return max(step, 1)
+}
+
+// applyMouse aplica un evento del raton a la imagen (ver apply)
+// La rueda cambia el zoom; arrastrar con el boton izquierdo mueve la imagen,
+// o la vista en sentido contrario si esta ampliada (la imagen sigue al puntero)
+func (v *Viewer) applyMouse(src *RenderImage, mouse ansi.MouseEvent) bool {
+	point := image.Pt(mouse.X, mouse.Y)
+
+	switch {
+	case mouse.Button == ansi.MouseWheelUp:
+		src.ZoomIn()
+		return true
+	case mouse.Button == ansi.MouseWheelDown:
+		src.ZoomOut()
+		return true
+	case mouse.Button == ansi.MouseLeft && mouse.Action == ansi.MousePress:
+		v.drag = dragState{from: point, active: true}
+	case mouse.Action == ansi.MouseRelease:
+		v.drag.active = false
+	case mouse.Action == ansi.MouseMotion && v.drag.active:
+		// Cada fila del terminal son dos pixeles de alto
+		delta := point.Sub(v.drag.from)
+		v.drag.from = point
+
+		if src.IsZoomed() { return src.PanViewport(-delta.X, -delta.Y * PPB) }
+		return src.pan(delta.X, delta.Y * PPB)
+	}
+	return false
+}
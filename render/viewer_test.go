@@ -0,0 +1,29 @@
+package terminal
+
+import (
+	"testing"
+	"time"
+)
+
+// TestPanAcceleration comprueba que el desplazamiento crece al repetir la misma accion
+// y que cambiar de direccion u otra accion en medio vuelve a empezar desde Step
+func TestPanAcceleration(t *testing.T) {
+	v := &Viewer{Step: 2, MaxStep: 5, Speed: time.Hour}
+	src := NewImage(testImage(4, 4, 0))
+
+	var steps []int
+	for range repeatsPerStep * 2 { steps = append(steps, v.step(PanRight)) }
+	want := []int{2, 2, 2, 2, 4, 4, 4, 4}
+	for i := range want {
+		if steps[i] != want[i] { t.Fatalf("repeticion %d: desplazamiento %d, se esperaba %d (%v)", i, steps[i], want[i], steps) }
+	}
+
+	for range repeatsPerStep * 2 { v.step(PanRight) }
+	if step := v.step(PanRight); step != 5 { t.Errorf("desplazamiento %d, se esperaba MaxStep 5", step) }
+
+	if step := v.step(PanLeft); step != 2 { t.Errorf("cambio de direccion: desplazamiento %d, se esperaba 2", step) }
+
+	for range repeatsPerStep * 2 { v.step(PanLeft) }
+	v.Apply(src, Help)
+	if step := v.step(PanLeft); step != 2 { t.Errorf("despues de otra accion: desplazamiento %d, se esperaba 2", step) }
+}
@@ -0,0 +1,153 @@
+package terminal
+
+import (
+	"image"
+	"math"
+)
+
+// ZoomFit ajusta la imagen a los bordes; es el valor por defecto de RenderImage.Zoom
+const ZoomFit = 0.0
+
+// Limites y paso del zoom interactivo
+var (
+	ZoomStep	= 1.25	// factor aplicado por ZoomIn y ZoomOut
+	MaxZoom		= 32.0	// pixeles de terminal por pixel de origen
+)
+
+// ZoomIn amplia la imagen un paso (ZoomStep) manteniendo el centro de la vista
+func (src *RenderImage) ZoomIn() {
+	src.SetZoom(src.currentScale() * ZoomStep)
+}
+
+// ZoomOut reduce la imagen un paso (ZoomStep) manteniendo el centro de la vista
+func (src *RenderImage) ZoomOut() {
+	src.SetZoom(src.currentScale() / ZoomStep)
+}
+
+// ZoomToFit vuelve a ajustar la imagen a los bordes
+func (src *RenderImage) ZoomToFit() {
+	src.SetZoom(ZoomFit)
+}
+
+// ZoomActual muestra la imagen a escala 1:1 (un pixel de origen por pixel de terminal)
+func (src *RenderImage) ZoomActual() {
+	src.SetZoom(1)
+}
+
+// SetZoom cambia la escala efectiva de la imagen (ZoomFit para ajustarla a los bordes)
+// El punto de la imagen que esta en el centro de la vista sigue en el centro despues del zoom
+// Sin imagen, o con una imagen vacia, no cambia nada
+func (src *RenderImage) SetZoom(zoom float64) {
+	if src.isEmpty() { return }
+	view := src.terminalView()
+
+	oldScale := view.CalculateScale()
+	oldSize := view.viewSize(oldScale)
+	centerX := (float64(src.Viewport.X) + float64(oldSize.X)/2) / oldScale
+	centerY := (float64(src.Viewport.Y) + float64(oldSize.Y)/2) / oldScale
+
+	if zoom != ZoomFit { zoom = math.Min(math.Max(zoom, view.minZoom()), MaxZoom) }
+	src.Zoom, view.Zoom = zoom, zoom
+
+	newScale := view.CalculateScale()
+	newSize := view.viewSize(newScale)
+	src.Viewport = image.Pt(
+		int(math.Round(centerX*newScale - float64(newSize.X)/2)),
+		int(math.Round(centerY*newScale - float64(newSize.Y)/2)),
+	)
+	src.Viewport = view.clampViewport(src.Viewport, newScale)
+}
+
+// PanViewport desplaza la porcion visible de la imagen ampliada
+// Devuelve false si con el zoom actual la imagen cabe entera y no hay nada que desplazar
+func (src *RenderImage) PanViewport(dx, dy int) bool {
+	if src.isEmpty() { return false }
+	view := src.terminalView()
+	scale := view.CalculateScale()
+	if !view.isPannable(scale) { return false }
+
+	last := src.Viewport
+	src.Viewport = view.clampViewport(src.Viewport.Add(image.Pt(dx, dy)), scale)
+
+	return !last.Eq(src.Viewport)
+}
+
+// IsZoomed indica si la imagen es mas grande que los bordes y se muestra por partes
+func (src *RenderImage) IsZoomed() bool {
+	view := src.terminalView()
+	return view.isPannable(view.CalculateScale())
+}
+
+// ScaleViewport escala unicamente la porcion visible de la imagen ampliada,
+// sin crear la imagen completa a esa escala
+func (src *RenderImage) ScaleViewport(scale float64) (*image.RGBA) {
+	zoomed := src.scaledSize(scale)
+	size := src.viewSize(scale)
+	offset := src.clampViewport(src.Viewport, scale)
+
+	dst := GetReusableRGBA(image.Rect(0,0, size.X, size.Y))
+	dr := image.Rect(0,0, zoomed.X, zoomed.Y).Sub(offset)
+
+	scaleRGBA(dst, dr, src.Image, src.SourceBounds(), src.scaleInterpolator(dr, src.SourceBounds()),
+		src.scaleWorkers(dst.Bounds(), src.SourceBounds()))
+
+	return dst
+}
+
+// terminalView devuelve una copia con los bordes ajustados al terminal actual,
+// que son los que se usaran al renderizar
+func (src *RenderImage) terminalView() (view RenderImage) {
+	view = *src
+
+	margin, err := src.AdjustLimitsToTerminal()
+	if err == nil && !margin.Empty() { view.Margin = margin }
+
+	return view
+}
+
+// currentScale devuelve la escala efectiva con los bordes del terminal actual
+func (src *RenderImage) currentScale() float64 {
+	view := src.terminalView()
+	return view.CalculateScale()
+}
+
+// isEmpty indica si no hay imagen o si esta (o su recorte) no tiene pixeles,
+// en cuyo caso no hay escala posible (fitScale y minZoom dividirian por cero)
+func (src *RenderImage) isEmpty() bool {
+	if src.Image == nil { return true }
+
+	size := src.sourceSize()
+	return size.X <= 0 || size.Y <= 0
+}
+
+// minZoom es la escala minima que deja la imagen en al menos un bloque
+func (src *RenderImage) minZoom() float64 {
+	size := src.sourceSize()
+	return float64(PPB) / float64(min(size.X, size.Y))
+}
+
+// scaledSize devuelve el tamaño de la imagen completa (o del recorte, ya transformado) a la escala dada
+func (src *RenderImage) scaledSize(scale float64) image.Point {
+	size := src.sourceSize()
+	return image.Pt(
+		int(math.Round(float64(size.X)* scale)),
+		int(math.Round(float64(size.Y)* scale)),
+	)
+}
+
+// viewSize devuelve el tamaño de la porcion visible: la imagen escalada limitada a los bordes
+func (src *RenderImage) viewSize(scale float64) image.Point {
+	zoomed := src.scaledSize(scale)
+	return image.Pt(min(zoomed.X, src.Margin.Dx()), min(zoomed.Y, src.Margin.Dy()))
+}
+
+// isPannable indica si a esa escala la imagen excede los bordes
+func (src *RenderImage) isPannable(scale float64) bool {
+	zoomed := src.scaledSize(scale)
+	return zoomed.X > src.Margin.Dx() || zoomed.Y > src.Margin.Dy()
+}
+
+// clampViewport mantiene el desplazamiento dentro de la imagen escalada
+func (src *RenderImage) clampViewport(offset image.Point, scale float64) image.Point {
+	return ClampToPoint(offset, src.scaledSize(scale).Sub(src.viewSize(scale)))
+}
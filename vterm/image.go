@@ -0,0 +1,61 @@
+package vterm
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/Leontas-9/terminal-go/ansi"
+)
+
+// Image reconstruye la imagen que dibujan los medios bloques de la pantalla:
+// cada celda son dos pixeles de alto (Width x Height*2)
+//	'▀'			arriba el color del texto y abajo el del fondo
+//	'▄'			arriba el color del fondo y abajo el del texto
+//	'█'			los dos pixeles con el color del texto
+//	'░' '▒' '▓'	los dos pixeles con el color del texto y un 25%, 50% o 75% de alfa
+//	otro		los dos pixeles con el color del fondo
+// Los colores por defecto del terminal quedan transparentes; Reverse intercambia texto y fondo
+// El resultado se puede comparar con la imagen reajustada que devuelve GetPNG
+func (s *Screen) Image() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0,0, s.Width, s.Height * 2))
+
+	for y := range s.Height {
+		for x := range s.Width {
+			upper, lower := cellPixels(s.cells[y * s.Width + x])
+			img.SetRGBA(x, y * 2, upper)
+			img.SetRGBA(x, y * 2 + 1, lower)
+		}
+	}
+
+	return img
+}
+
+// cellPixels devuelve el color de los pixeles superior e inferior de una celda (ver Image)
+func cellPixels(cell Cell) (upper, lower color.RGBA) {
+	fg, bg := opaque(cell.Style.Foreground), opaque(cell.Style.Background)
+	if cell.Style.Attrs & ansi.Reverse != 0 { fg, bg = bg, fg }
+
+	switch cell.Rune {
+	case ansi.UpperHalfBlock:	return fg, bg
+	case ansi.LowerHalfBlock:	return bg, fg
+	case '█':					return fg, fg
+	case '░':					return shade(fg, 1), shade(fg, 1)
+	case '▒':					return shade(fg, 2), shade(fg, 2)
+	case '▓':					return shade(fg, 3), shade(fg, 3)
+	}
+	return bg, bg
+}
+
+// opaque deja transparente el color por defecto (alfa <= ALPHA_1) y opaco el resto
+func opaque(c color.RGBA) color.RGBA {
+	if c.A <= ansi.ALPHA_1 { return color.RGBA{} }
+
+	c.A = 255
+	return c
+}
+
+// shade devuelve el color con 'level' cuartos de alfa, premultiplicado como image.RGBA
+func shade(c color.RGBA, level int) color.RGBA {
+	scale := func(v uint8) uint8 { return uint8(int(v) * level / 4) }
+	return color.RGBA{scale(c.R), scale(c.G), scale(c.B), scale(c.A)}
+}
@@ -0,0 +1,457 @@
+package vterm
+
+/*
+Terminal virtual sin pantalla: aplica una salida ANSI (por ejemplo la de GetPNG) a una
+cuadricula de celdas para poder comprobar el resultado sin un terminal real.
+Soporta:
+	texto con autowrap (DECAWM, ?7), \r \n \b \t
+	movimientos del cursor: CUU, CUD, CUF, CUB, CNL (MoveDown_Start), CPL, CHA (MoveToColumn),
+	CUP y HVP (MoveTo), VPA, HPA, ESC 7 / ESC 8, CSI s / CSI u
+	borrados: ED (J), EL (K), ECH (X), con el color de fondo actual
+	SGR completo (ver ansi.Style.ApplySGR)
+	pantalla alternativa (?47, ?1047, ?1049), cursor visible (?25) y el resto de modos privados
+	IND, NEL, RI y RIS (ESC D, ESC E, ESC M, ESC c)
+	region de desplazamiento (DECSTBM), SU y SD (CSI S, CSI T), IL y DL (CSI L, CSI M)
+Las coordenadas son de 0 a Width-1 y de 0 a Height-1.
+*/
+
+import (
+	"image"
+	"strings"
+	"unicode"
+
+	"github.com/Leontas-9/terminal-go/ansi"
+)
+
+// Modos privados (DECSET) con tratamiento especial
+const (
+	ModeAutoWrap		= 7
+	ModeCursorVisible	= 25
+	ModeAltScreen		= 47
+	ModeAltScreenClear	= 1047
+	ModeAltScreenSave	= 1049
+)
+
+// tabWidth es la distancia entre las paradas de tabulacion
+const tabWidth = 8
+
+// Cell es una celda de la pantalla: un caracter y su estilo
+// Una celda vacia tiene Rune ' '
+type Cell struct {
+	Rune	rune
+	Style	ansi.Style
+}
+
+// Screen es un terminal virtual de Width x Height celdas
+// Implementa io.Writer: todo lo que se escribe se interpreta como salida de un programa
+type Screen struct {
+	Width	int
+	Height	int
+
+	// Si es true '\n' tambien vuelve a la primera columna, como hace el tty con ONLCR
+	// (activo por defecto en NewScreen)
+	NewlineCR	bool
+
+	main		[]Cell
+	alt			[]Cell
+	cells		[]Cell
+	isAlt		bool
+
+	cursor		image.Point
+	saved		savedCursor
+	altSaved	savedCursor
+	style		ansi.Style
+
+	// El cursor escribio en la ultima columna: el siguiente caracter salta de linea
+	pendingWrap	bool
+
+	// Region de desplazamiento: filas de top a bottom, inclusivas (DECSTBM)
+	top			int
+	bottom		int
+
+	modes		map[int]bool
+	title		string
+	parser		*ansi.Parser
+}
+
+// savedCursor es el estado que guardan DECSC (ESC 7) y ?1049
+type savedCursor struct {
+	position	image.Point
+	style		ansi.Style
+}
+
+// NewScreen crea una pantalla vacia con el cursor en (0,0), autowrap y cursor visible
+func NewScreen(width, height int) *Screen {
+	width, height = max(width, 1), max(height, 1)
+
+	screen := &Screen{
+		Width:		width,
+		Height:		height,
+		NewlineCR:	true,
+		parser:		ansi.NewParser(),
+	}
+	screen.Reset()
+
+	return screen
+}
+
+// Reset vuelve la pantalla al estado inicial (como RIS, ESC c)
+func (s *Screen) Reset() {
+	s.main = blankCells(s.Width * s.Height, ansi.Style{})
+	s.alt = blankCells(s.Width * s.Height, ansi.Style{})
+	s.cells, s.isAlt = s.main, false
+
+	s.cursor = image.Point{}
+	s.saved, s.altSaved = savedCursor{}, savedCursor{}
+	s.style = ansi.Style{}
+	s.pendingWrap = false
+	s.top, s.bottom = 0, s.Height - 1
+	s.modes = map[int]bool{ModeAutoWrap: true, ModeCursorVisible: true}
+	s.title = ""
+}
+
+// blankCells crea n celdas vacias con el fondo del estilo
+func blankCells(n int, style ansi.Style) []Cell {
+	cells := make([]Cell, n)
+	for i := range cells { cells[i] = blank(style) }
+	return cells
+}
+
+// blank es la celda que deja un borrado: un espacio con el fondo actual (BCE)
+func blank(style ansi.Style) Cell {
+	return Cell{Rune: ' ', Style: ansi.Style{Background: style.Background}}
+}
+
+// Write interpreta la salida ANSI y la aplica a la pantalla; nunca devuelve error
+// Una secuencia o un caracter cortado al final de p se completa en la siguiente llamada
+func (s *Screen) Write(p []byte) (n int, err error) {
+	s.parser.Parse(p, s.apply)
+	return len(p), nil
+}
+
+// WriteString es como Write para una cadena
+func (s *Screen) WriteString(text string) (n int, err error) {
+	return s.Write([]byte(text))
+}
+
+// Cell devuelve la celda de la columna x y la fila y (una celda vacia si esta fuera de la pantalla)
+func (s *Screen) Cell(x, y int) Cell {
+	if x < 0 || y < 0 || x >= s.Width || y >= s.Height { return blank(ansi.Style{}) }
+	return s.cells[y * s.Width + x]
+}
+
+// Cursor devuelve la posicion del cursor
+func (s *Screen) Cursor() image.Point {
+	return s.cursor
+}
+
+// Style devuelve el estilo con el que se escribe el siguiente caracter
+func (s *Screen) Style() ansi.Style {
+	return s.style
+}
+
+// Mode indica si el modo privado esta activo (ModeAutoWrap, ModeCursorVisible, ansi.MouseSGR...)
+func (s *Screen) Mode(mode int) bool {
+	return s.modes[mode]
+}
+
+// AltScreen indica si esta activa la pantalla alternativa
+func (s *Screen) AltScreen() bool {
+	return s.isAlt
+}
+
+// Title devuelve el ultimo titulo de ventana (OSC 0 u OSC 2)
+func (s *Screen) Title() string {
+	return s.title
+}
+
+// Line devuelve el texto de la fila y sin estilos ni espacios finales
+func (s *Screen) Line(y int) string {
+	if y < 0 || y >= s.Height { return "" }
+
+	runes := make([]rune, s.Width)
+	for x := range runes { runes[x] = s.cells[y * s.Width + x].Rune }
+
+	return strings.TrimRight(string(runes), " ")
+}
+
+// String devuelve el texto de la pantalla, una fila por linea, sin las filas vacias del final
+func (s *Screen) String() string {
+	lines := make([]string, s.Height)
+	for y := range lines { lines[y] = s.Line(y) }
+
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+// Resize cambia el tamaño de la pantalla conservando las celdas de arriba a la izquierda
+// La region de desplazamiento vuelve a ser la pantalla completa
+func (s *Screen) Resize(width, height int) {
+	width, height = max(width, 1), max(height, 1)
+
+	resize := func(cells []Cell) []Cell {
+		resized := blankCells(width * height, ansi.Style{})
+		for y := range min(height, s.Height) {
+			copy(resized[y * width : y * width + min(width, s.Width)], cells[y * s.Width:])
+		}
+		return resized
+	}
+	s.main, s.alt = resize(s.main), resize(s.alt)
+	s.cells = s.main
+	if s.isAlt { s.cells = s.alt }
+
+	s.Width, s.Height = width, height
+	s.top, s.bottom = 0, height - 1
+	s.moveTo(s.cursor.X, s.cursor.Y)
+}
+
+// apply aplica un token de la salida
+func (s *Screen) apply(token ansi.Token) {
+	switch token.Kind {
+	case ansi.TokenPrint:
+		for _, r := range token.Text { s.print(r) }
+	case ansi.TokenControl:
+		s.control(token.Control)
+	case ansi.TokenSGR:
+		if token.Private == 0 && len(token.Intermediate) == 0 { s.style = s.style.ApplySGR(token.Params) }
+	case ansi.TokenCursor:
+		s.moveCursor(token)
+	case ansi.TokenErase:
+		s.erase(token)
+	case ansi.TokenCSI:
+		s.csi(token)
+	case ansi.TokenEscape:
+		s.escape(token)
+	case ansi.TokenOSC:
+		if token.Command == 0 || token.Command == 2 { s.title = string(token.Payload) }
+	}
+}
+
+// print escribe un caracter en el cursor y lo avanza
+func (s *Screen) print(r rune) {
+	if !unicode.IsPrint(r) { return }
+
+	if s.pendingWrap {
+		s.pendingWrap = false
+		s.cursor.X = 0
+		s.lineFeed()
+	}
+
+	s.cells[s.cursor.Y * s.Width + s.cursor.X] = Cell{Rune: r, Style: s.style}
+
+	switch {
+	case s.cursor.X < s.Width - 1:	s.cursor.X++
+	case s.modes[ModeAutoWrap]:		s.pendingWrap = true
+	}
+}
+
+// control aplica un control C0
+func (s *Screen) control(control byte) {
+	switch control {
+	case '\r':
+		s.moveTo(0, s.cursor.Y)
+	case '\n', '\v', '\f':
+		x := s.cursor.X
+		if s.NewlineCR { x = 0 }
+		s.lineFeed()
+		s.moveTo(x, s.cursor.Y)
+	case '\b':
+		s.moveTo(s.cursor.X - 1, s.cursor.Y)
+	case '\t':
+		s.moveTo((s.cursor.X / tabWidth + 1) * tabWidth, s.cursor.Y)
+	}
+}
+
+// lineFeed baja una fila, desplazando la region hacia arriba en su ultima fila
+func (s *Screen) lineFeed() {
+	s.pendingWrap = false
+	switch {
+	case s.cursor.Y == s.bottom:	s.scrollUp(s.top, 1)
+	case s.cursor.Y < s.Height - 1:	s.cursor.Y++
+	}
+}
+
+// reverseLineFeed sube una fila, desplazando la region hacia abajo en su primera fila
+func (s *Screen) reverseLineFeed() {
+	s.pendingWrap = false
+	switch {
+	case s.cursor.Y == s.top:	s.scrollDown(s.top, 1)
+	case s.cursor.Y > 0:		s.cursor.Y--
+	}
+}
+
+// scrollUp desplaza hacia arriba las filas desde 'top' hasta el final de la region
+// y deja 'lines' filas vacias abajo
+func (s *Screen) scrollUp(top, lines int) {
+	end := (s.bottom + 1) * s.Width
+	lines = min(lines, s.bottom + 1 - top)
+	copy(s.cells[top * s.Width : end], s.cells[(top + lines) * s.Width : end])
+	s.fill(end - lines * s.Width, end)
+}
+
+// scrollDown desplaza hacia abajo las filas desde 'top' hasta el final de la region
+// y deja 'lines' filas vacias arriba
+func (s *Screen) scrollDown(top, lines int) {
+	end := (s.bottom + 1) * s.Width
+	lines = min(lines, s.bottom + 1 - top)
+	copy(s.cells[(top + lines) * s.Width : end], s.cells[top * s.Width : end - lines * s.Width])
+	s.fill(top * s.Width, (top + lines) * s.Width)
+}
+
+// setScrollRegion fija la region de desplazamiento (DECSTBM) y mueve el cursor a (0,0)
+// Una region de menos de dos filas no es valida y se ignora
+func (s *Screen) setScrollRegion(token ansi.Token) {
+	top, bottom := token.Param(0, 1) - 1, min(token.Param(1, s.Height), s.Height) - 1
+	if top >= bottom { return }
+
+	s.top, s.bottom = top, bottom
+	s.moveTo(0, 0)
+}
+
+// editLines inserta (IL) o borra (DL) filas en la del cursor, si esta dentro de la region
+func (s *Screen) editLines(token ansi.Token) {
+	if s.cursor.Y < s.top || s.cursor.Y > s.bottom { return }
+
+	if token.Final == 'L' { s.scrollDown(s.cursor.Y, token.Param(0, 1))
+	} else { s.scrollUp(s.cursor.Y, token.Param(0, 1)) }
+	s.moveTo(0, s.cursor.Y)
+}
+
+// fill borra las celdas [from, to) con el fondo actual
+func (s *Screen) fill(from, to int) {
+	cell := blank(s.style)
+	for i := max(from, 0); i < min(to, len(s.cells)); i++ { s.cells[i] = cell }
+}
+
+// moveTo mueve el cursor dentro de la pantalla
+func (s *Screen) moveTo(x, y int) {
+	s.pendingWrap = false
+	s.cursor = image.Pt(min(max(x, 0), s.Width - 1), min(max(y, 0), s.Height - 1))
+}
+
+// moveCursor aplica un movimiento del cursor (TokenCursor)
+func (s *Screen) moveCursor(token ansi.Token) {
+	if token.Private != 0 || len(token.Intermediate) != 0 { return }
+
+	n := token.Param(0, 1)
+	x, y := s.cursor.X, s.cursor.Y
+
+	switch token.Final {
+	case 'A':		s.moveTo(x, y - n)
+	case 'B', 'e':	s.moveTo(x, y + n)
+	case 'C', 'a':	s.moveTo(x + n, y)
+	case 'D':		s.moveTo(x - n, y)
+	case 'E':		s.moveTo(0, y + n)
+	case 'F':		s.moveTo(0, y - n)
+	case 'G', '`':	s.moveTo(n - 1, y)
+	case 'd':		s.moveTo(x, n - 1)
+	case 'H', 'f':	s.moveTo(token.Param(1, 1) - 1, n - 1)
+	}
+}
+
+// erase aplica un borrado (TokenErase)
+func (s *Screen) erase(token ansi.Token) {
+	if token.Private != 0 || len(token.Intermediate) != 0 { return }
+
+	cursor := s.cursor.Y * s.Width + s.cursor.X
+	line := s.cursor.Y * s.Width
+
+	switch token.Final {
+	case 'J':
+		switch max(token.Param(0, 0), 0) {
+		case 0:	s.fill(cursor, len(s.cells))
+		case 1:	s.fill(0, cursor + 1)
+		case 2:	s.fill(0, len(s.cells))
+		}
+	case 'K':
+		switch max(token.Param(0, 0), 0) {
+		case 0:	s.fill(cursor, line + s.Width)
+		case 1:	s.fill(line, cursor + 1)
+		case 2:	s.fill(line, line + s.Width)
+		}
+	case 'X':
+		s.fill(cursor, min(cursor + token.Param(0, 1), line + s.Width))
+	}
+	s.pendingWrap = false
+}
+
+// csi aplica los modos (h, l), el guardado del cursor (s, u), la region de desplazamiento (r),
+// SU y SD (S, T) e IL y DL (L, M)
+func (s *Screen) csi(token ansi.Token) {
+	if len(token.Intermediate) != 0 { return }
+
+	switch {
+	case token.Private == 0 && token.Final == 'r':
+		s.setScrollRegion(token)
+	case token.Private == 0 && token.Final == 'S':
+		s.scrollUp(s.top, token.Param(0, 1))
+	case token.Private == 0 && token.Final == 'T' && len(token.Params) <= 1:
+		// Con mas parametros, CSI T es el seguimiento del raton de xterm
+		s.scrollDown(s.top, token.Param(0, 1))
+	case token.Private == 0 && (token.Final == 'L' || token.Final == 'M'):
+		s.editLines(token)
+	case token.Private == '?' && (token.Final == 'h' || token.Final == 'l'):
+		for _, param := range token.Params { s.setMode(param.Value, token.Final == 'h') }
+	case token.Private == 0 && token.Final == 's':
+		s.saveCursor()
+	case token.Private == 0 && token.Final == 'u':
+		s.restoreCursor()
+	}
+}
+
+// escape aplica ESC 7, ESC 8, IND, NEL, RI y RIS
+func (s *Screen) escape(token ansi.Token) {
+	if len(token.Intermediate) != 0 { return }
+
+	switch token.Final {
+	case '7':	s.saveCursor()
+	case '8':	s.restoreCursor()
+	case 'D':	s.lineFeed()
+	case 'E':	s.lineFeed(); s.moveTo(0, s.cursor.Y)
+	case 'M':	s.reverseLineFeed()
+	case 'c':	s.Reset()
+	}
+}
+
+// setMode activa o desactiva un modo privado
+func (s *Screen) setMode(mode int, isActive bool) {
+	switch mode {
+	case ModeAltScreen, ModeAltScreenClear, ModeAltScreenSave:
+		s.setAltScreen(mode, isActive)
+	case ModeAutoWrap:
+		if !isActive { s.pendingWrap = false }
+	}
+	s.modes[mode] = isActive
+}
+
+// setAltScreen cambia entre la pantalla principal y la alternativa
+// ?1049 guarda el cursor y borra la alternativa al entrar; ?1047 la borra al salir
+func (s *Screen) setAltScreen(mode int, isActive bool) {
+	if isActive == s.isAlt { return }
+
+	if isActive {
+		s.cells, s.isAlt = s.alt, true
+		if mode == ModeAltScreenSave {
+			s.altSaved = savedCursor{s.cursor, s.style}
+			s.fill(0, len(s.cells))
+		}
+		return
+	}
+
+	if mode == ModeAltScreenClear || mode == ModeAltScreenSave { s.fill(0, len(s.cells)) }
+	s.cells, s.isAlt = s.main, false
+	if mode == ModeAltScreenSave {
+		s.moveTo(s.altSaved.position.X, s.altSaved.position.Y)
+		s.style = s.altSaved.style
+	}
+}
+
+// saveCursor guarda la posicion y el estilo (DECSC)
+func (s *Screen) saveCursor() {
+	s.saved = savedCursor{s.cursor, s.style}
+}
+
+// restoreCursor vuelve a la posicion y el estilo guardados (DECRC)
+func (s *Screen) restoreCursor() {
+	s.moveTo(s.saved.position.X, s.saved.position.Y)
+	s.style = s.saved.style
+}
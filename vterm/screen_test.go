@@ -0,0 +1,198 @@
+package vterm
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/Leontas-9/terminal-go/ansi"
+	terminal "github.com/Leontas-9/terminal-go/render"
+)
+
+// write crea una pantalla de 'width' x 'height' y le escribe 'output'
+func write(width, height int, output ...string) *Screen {
+	screen := NewScreen(width, height)
+	for _, text := range output { screen.WriteString(text) }
+	return screen
+}
+
+func TestCursor(t *testing.T) {
+	tests := []struct {
+		name	string
+		output	string
+		want	image.Point
+	}{
+		{"MoveTo", ansi.MoveTo(5, 3), image.Pt(4, 2)},
+		{"MoveTo fuera de la pantalla", ansi.MoveTo(500, 500), image.Pt(19, 9)},
+		{"MoveTo sin parametros", "abc\x1b[H", image.Pt(0, 0)},
+		{"MoveToColumn", ansi.MoveTo(5, 3) + ansi.MoveToColumn(12), image.Pt(11, 2)},
+		{"MoveToColumn 0", ansi.MoveTo(5, 3) + ansi.MoveToColumn(0), image.Pt(0, 2)},
+		{"MoveDown_Start", ansi.MoveTo(5, 3) + ansi.MoveDown_Start(2), image.Pt(0, 4)},
+		{"MoveDown_Start en la ultima fila", ansi.MoveTo(5, 10) + ansi.MoveDown_Start(3), image.Pt(0, 9)},
+		{"movimientos relativos", "\x1b[4;4H\x1b[2A\x1b[3C\x1b[B\x1b[D", image.Pt(5, 2)},
+		{"VPA y HPA", "\x1b[7d\x1b[3`", image.Pt(2, 6)},
+		{"texto y controles", "hola\r\nmundo\tx\b", image.Pt(8, 1)},
+		{"guardar y restaurar", ansi.MoveTo(3, 4) + ansi.SaveCursor() + ansi.MoveTo(9, 9) + ansi.RestoreCursor(), image.Pt(2, 3)},
+	}
+
+	for _, test := range tests {
+		screen := write(20, 10, test.output)
+		if got := screen.Cursor(); got != test.want { t.Errorf("%s: cursor = %v, se esperaba %v", test.name, got, test.want) }
+	}
+}
+
+func TestSGR(t *testing.T) {
+	red := color.RGBA{R: 10, G: 20, B: 30, A: 255}
+	screen := write(10, 2, "\x1b[1;38;2;10;20;30mA\x1b[22;48:2::1:2:3mB\x1b[39;7mC\x1b[0mD")
+
+	tests := []struct {
+		x		int
+		want	ansi.Style
+	}{
+		{0, ansi.Style{Foreground: red, Attrs: ansi.Bold}},
+		{1, ansi.Style{Foreground: red, Background: color.RGBA{R: 1, G: 2, B: 3, A: 255}}},
+		{2, ansi.Style{Background: color.RGBA{R: 1, G: 2, B: 3, A: 255}, Attrs: ansi.Reverse}},
+		{3, ansi.Style{}},
+	}
+
+	for _, test := range tests {
+		cell := screen.Cell(test.x, 0)
+		if cell.Style != test.want { t.Errorf("celda %d (%q): estilo %+v, se esperaba %+v", test.x, cell.Rune, cell.Style, test.want) }
+	}
+	if screen.Line(0) != "ABCD" { t.Errorf("Line(0) = %q", screen.Line(0)) }
+}
+
+// TestErase comprueba ED y EL; lo borrado queda con el fondo actual (BCE)
+func TestErase(t *testing.T) {
+	fill := strings.Repeat("abcdefgh\r\n", 3) + "abcdefgh"
+	blue := color.RGBA{B: 200, A: 255}
+
+	tests := []struct {
+		name	string
+		output	string
+		want	string
+	}{
+		{"ED 0", ansi.MoveTo(4, 2) + ansi.EraseScreen_FromCursor(), "abcdefgh\nabc"},
+		{"ED 1", ansi.MoveTo(4, 2) + ansi.EraseScreen_ToCursor(), "\n    efgh\nabcdefgh\nabcdefgh"},
+		{"ED 2", ansi.EraseScreen(), ""},
+		{"EL 0", ansi.MoveTo(4, 2) + ansi.EraseLine_FromCursor(), "abcdefgh\nabc\nabcdefgh\nabcdefgh"},
+		{"EL 1", ansi.MoveTo(4, 2) + ansi.EraseLine_ToCursor(), "abcdefgh\n    efgh\nabcdefgh\nabcdefgh"},
+		{"EL 2", ansi.MoveTo(4, 2) + ansi.EraseLine(), "abcdefgh\n\nabcdefgh\nabcdefgh"},
+		{"ECH", ansi.MoveTo(2, 3) + "\x1b[3X", "abcdefgh\nabcdefgh\na   efgh\nabcdefgh"},
+	}
+
+	for _, test := range tests {
+		screen := write(8, 4, fill, "\x1b[44;48;2;0;0;200m", test.output)
+		if got := screen.String(); got != test.want { t.Errorf("%s: pantalla\n%s\nse esperaba\n%s", test.name, got, test.want) }
+
+		for y := range screen.Height {
+			for x := range screen.Width {
+				cell := screen.Cell(x, y)
+				if cell.Rune == ' ' && cell.Style.Background != blue { t.Errorf("%s: celda (%d,%d) borrada sin el fondo actual", test.name, x, y) }
+			}
+		}
+	}
+}
+
+// TestAltScreen comprueba que ?1049 guarda el cursor y el estilo, limpia la pantalla
+// alternativa y devuelve la principal intacta
+func TestAltScreen(t *testing.T) {
+	screen := write(10, 4, "principal", ansi.MoveTo(3, 2), "\x1b[1m")
+
+	screen.WriteString(ansi.AlternativeScreen(true))
+	if !screen.AltScreen() || screen.String() != "" { t.Fatalf("pantalla alternativa: activa = %v, texto %q", screen.AltScreen(), screen.String()) }
+
+	screen.WriteString("\x1b[0m" + ansi.MoveTo(1, 4) + "alt")
+	if screen.String() != "\n\n\nalt" { t.Errorf("pantalla alternativa: %q", screen.String()) }
+
+	screen.WriteString(ansi.AlternativeScreen(false))
+	if screen.AltScreen() || screen.String() != "principal" { t.Errorf("pantalla principal: activa = %v, texto %q", !screen.AltScreen(), screen.String()) }
+	if screen.Cursor() != image.Pt(2, 1) { t.Errorf("cursor = %v, se esperaba (2,1)", screen.Cursor()) }
+	if screen.Style().Attrs != ansi.Bold { t.Errorf("estilo = %+v, se esperaba negrita", screen.Style()) }
+
+	// Al volver a entrar la alternativa esta vacia
+	screen.WriteString(ansi.AlternativeScreen(true))
+	if screen.String() != "" { t.Errorf("la pantalla alternativa no se limpio: %q", screen.String()) }
+}
+
+// TestAutoWrap comprueba el salto pendiente: escribir en la ultima columna no salta de linea
+// hasta el siguiente caracter, y un movimiento o un \r en medio lo cancela
+func TestAutoWrap(t *testing.T) {
+	tests := []struct {
+		name	string
+		output	string
+		want	string
+		cursor	image.Point
+	}{
+		{"ultima columna", "abcde", "abcde", image.Pt(4, 0)},
+		{"salto pendiente", "abcdef", "abcde\nf", image.Pt(1, 1)},
+		{"\\r cancela el salto", "abcde\rX", "Xbcde", image.Pt(1, 0)},
+		{"movimiento cancela el salto", "abcde" + ansi.MoveToColumn(2) + "X", "aXcde", image.Pt(2, 0)},
+		{"desplaza en la ultima fila", "abcdefghijklmnop", "fghij\nklmno\np", image.Pt(1, 2)},
+		{"sin autowrap", "\x1b[?7labcdefg", "abcdg", image.Pt(4, 0)},
+		{"autowrap de nuevo", "\x1b[?7l\x1b[?7habcdefg", "abcde\nfg", image.Pt(2, 1)},
+	}
+
+	for _, test := range tests {
+		screen := write(5, 3, test.output)
+		if got := screen.String(); got != test.want { t.Errorf("%s: pantalla %q, se esperaba %q", test.name, got, test.want) }
+		if got := screen.Cursor(); got != test.cursor { t.Errorf("%s: cursor = %v, se esperaba %v", test.name, got, test.cursor) }
+	}
+}
+
+// fixture crea una imagen opaca pequeña con colores distintos en cada pixel
+func fixture(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0,0, width, height))
+	for y := range height {
+		for x := range width {
+			img.SetRGBA(x, y, color.RGBA{R: uint8(x * 40 + 10), G: uint8(y * 30 + 20), B: uint8((x + y) * 15), A: 255})
+		}
+	}
+	return img
+}
+
+// TestImage aplica la salida de GetPNG a la pantalla y compara los pixeles que reconstruye
+// Image con los de la imagen de origen, con la posicion inicial en fila par e impar
+func TestImage(t *testing.T) {
+	src := fixture(6, 5)
+
+	for _, at := range []image.Point{{0, 0}, {3, 2}, {2, 3}} {
+		img := terminal.NewImage(src)
+		img.InitialPoint = at
+		output, _, err := img.GetPNG()
+		if err != nil { t.Fatal(err) }
+
+		screen := write(12, 6, string(output))
+		got := screen.Image()
+
+		for y := range src.Rect.Dy() {
+			for x := range src.Rect.Dx() {
+				want := src.RGBAAt(x, y)
+				if pixel := got.RGBAAt(at.X + x, at.Y + y); pixel != want {
+					t.Fatalf("posicion %v: pixel (%d,%d) = %v, se esperaba %v", at, x, y, pixel, want)
+				}
+			}
+		}
+
+		// Fuera de la imagen no se dibuja nada
+		drawn := image.Rectangle{Min: at, Max: at.Add(src.Rect.Size())}
+		for y := range got.Rect.Dy() {
+			for x := range got.Rect.Dx() {
+				if image.Pt(x, y).In(drawn) { continue }
+				if pixel := got.RGBAAt(x, y); pixel != (color.RGBA{}) {
+					t.Fatalf("posicion %v: pixel (%d,%d) = %v fuera de la imagen", at, x, y, pixel)
+				}
+			}
+		}
+	}
+}
+
+// ExampleScreen muestra la pantalla despues de una salida con movimientos y borrados
+func ExampleScreen() {
+	screen := NewScreen(12, 3)
+	screen.WriteString("hola mundo" + ansi.MoveTo(6, 1) + ansi.EraseLine_FromCursor() + "gente")
+	fmt.Println(screen.Line(0))
+	// Output: hola gente
+}